@@ -0,0 +1,166 @@
+package erax
+
+import (
+	"bytes"
+	"strings"
+)
+
+// FormatGitHubAnnotations renders every root cause of the error as a GitHub Actions workflow command,
+// so CI failures show up as annotations:
+//
+//	::error file=app/user.go,line=42,title=service error::db timeout
+//
+// The location comes from the "file" and "line" metadata of the root cause, or of its closest ancestor that has them.
+// The title is the message of the outermost error that has one. Lines are separated by "\n".
+func FormatGitHubAnnotations(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	walkRootCauses(err, func(leaf error, ancestors []*errorType) {
+		path, line := leafLocation(leaf, ancestors)
+
+		buf.WriteString("::error")
+
+		sep := byte(' ')
+		writeProperty := func(name, value string) {
+			if value == "" {
+				return
+			}
+			buf.WriteByte(sep)
+			buf.WriteString(name)
+			buf.WriteByte('=')
+			writeGitHubEscaped(buf, value, true)
+			sep = ','
+		}
+
+		writeProperty("file", path)
+		writeProperty("line", line)
+		writeProperty("title", leafTitle(ancestors))
+
+		buf.WriteString("::")
+		writeGitHubEscaped(buf, leafMessage(leaf), false)
+		buf.WriteByte('\n')
+	})
+
+	res := buf.String()
+
+	if buf.Cap() <= 16384 {
+		bufferPool.Put(buf)
+	}
+
+	return res
+}
+
+// FormatProblems renders every root cause of the error on a line of its own,
+// in the "file:line: error: message" format most CI problem matchers understand:
+//
+//	app/user.go:42: error: service error: db timeout
+//
+// Root causes without a location are rendered as "error: message". The location and the title
+// are found the same way as by FormatGitHubAnnotations. Multi-line messages are joined into a single line.
+func FormatProblems(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	walkRootCauses(err, func(leaf error, ancestors []*errorType) {
+		path, line := leafLocation(leaf, ancestors)
+
+		if path != "" {
+			buf.WriteString(path)
+			buf.WriteByte(':')
+			if line != "" {
+				buf.WriteString(line)
+				buf.WriteByte(':')
+			}
+			buf.WriteByte(' ')
+		}
+
+		buf.WriteString("error: ")
+		if title := leafTitle(ancestors); title != "" {
+			buf.WriteString(singleLine(title))
+			buf.WriteString(": ")
+		}
+		buf.WriteString(singleLine(leafMessage(leaf)))
+		buf.WriteByte('\n')
+	})
+
+	res := buf.String()
+
+	if buf.Cap() <= 16384 {
+		bufferPool.Put(buf)
+	}
+
+	return res
+}
+
+// leafLocation returns the source location of a root cause, taken from its own metadata
+// or from the metadata of its closest ancestor that has a location.
+func leafLocation(leaf error, ancestors []*errorType) (string, string) {
+	if e, isErax := asErax(leaf); isErax {
+		if path, line := metaLocation(e.meta); path != "" {
+			return path, line
+		}
+	}
+
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if path, line := metaLocation(ancestors[i].meta); path != "" {
+			return path, line
+		}
+	}
+
+	return "", ""
+}
+
+// leafTitle returns the message of the outermost ancestor of a root cause that has one.
+func leafTitle(ancestors []*errorType) string {
+	for _, e := range ancestors {
+		if e.msg != "" {
+			return e.msg
+		}
+	}
+	return ""
+}
+
+func leafMessage(leaf error) string {
+	if e, isErax := asErax(leaf); isErax {
+		return e.msg
+	}
+	return leaf.Error()
+}
+
+// writeGitHubEscaped writes text escaped for a workflow command: as a property value if property is set,
+// or as the message otherwise.
+func writeGitHubEscaped(buf *bytes.Buffer, text string, property bool) {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '%':
+			buf.WriteString("%25")
+		case c == '\r':
+			buf.WriteString("%0D")
+		case c == '\n':
+			buf.WriteString("%0A")
+		case c == ':' && property:
+			buf.WriteString("%3A")
+		case c == ',' && property:
+			buf.WriteString("%2C")
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+// singleLine joins the lines of text with spaces.
+func singleLine(text string) string {
+	if strings.IndexAny(text, "\r\n") == -1 {
+		return text
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
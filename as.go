@@ -0,0 +1,72 @@
+package erax
+
+// As finds the first error in the tree of type T, in the order Walk visits them.
+//
+// Unlike errors.As, it searches every branch of WrapWithErrors nodes, and the errors wrapped by non-erax errors.
+// An error matches if it is of type T, or if it has an As(any) bool method that accepts a pointer to T.
+func As[T any](err error) (T, bool) {
+	var found T
+	var ok bool
+
+	Walk(err, func(n Node, _ []int, _ int) WalkAction {
+		found, ok = asTarget[T](n.Err())
+		if ok {
+			return WalkStop
+		}
+		return WalkContinue
+	})
+
+	return found, ok
+}
+
+// AsAll returns every error in the tree of type T, in the order Walk visits them.
+//
+// Errors match the same way as for As. Errors reachable more than once are returned once.
+func AsAll[T any](err error) []T {
+	var found []T
+
+	Walk(err, func(n Node, _ []int, _ int) WalkAction {
+		if t, ok := asTarget[T](n.Err()); ok {
+			found = append(found, t)
+		}
+		return WalkContinue
+	})
+
+	return found
+}
+
+// Match is a node found by FindAll, with its path in the tree.
+type Match struct {
+	Node Node
+	// Path holds the indexes of the node and its ancestors among the children of their parents,
+	// the same way as for Walk.
+	Path []int
+}
+
+// FindAll returns every node of the tree pred returns true for, in the order Walk visits them.
+func FindAll(err error, pred func(n Node) bool) []Match {
+	var found []Match
+
+	Walk(err, func(n Node, path []int, _ int) WalkAction {
+		if pred(n) {
+			found = append(found, Match{Node: n, Path: append([]int(nil), path...)})
+		}
+		return WalkContinue
+	})
+
+	return found
+}
+
+// asTarget converts a single error to T, without looking at the errors it wraps.
+func asTarget[T any](err error) (T, bool) {
+	if t, ok := err.(T); ok {
+		return t, true
+	}
+
+	var t T
+	if x, ok := err.(interface{ As(any) bool }); ok && x.As(&t) {
+		return t, true
+	}
+
+	return t, false
+}
@@ -1,5 +1,15 @@
 package erax
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Cast converts a standard Go error tree to an erax error tree, so Format and JSON show its structure.
+//
+// Every converted error keeps a reference to the original one, so errors.As and errors.Is still reach it,
+// and Error still returns its message. Erax errors are returned unchanged.
 func Cast(err error) error {
 	if err == nil {
 		return nil
@@ -12,32 +22,184 @@ func Cast(err error) error {
 	return cast(err)
 }
 
-// TODO: rewrite it without recursion
+// cast converts a standard Go error tree to an erax error tree.
+//
+// Only the errors wrapping other errors are converted, and each of them is kept as the origin of its node.
+// Erax errors found inside the tree are reused as they are.
+//
+// Errors reachable more than once are converted only once, so shared nodes stay shared,
+// and an error that wraps one of its ancestors becomes a cycle the traversals can detect.
+//
+// The tree is converted without recursion, so deep chains can't overflow the stack,
+// and the errors past the limits set by SetMaxCastDepth and SetMaxCastNodes are replaced with a truncation marker.
 func cast(err error) error {
-	if err == nil {
-		return nil
-	}
+	var root error
+	var seen map[error]*errorType
+
+	stack := []castTask{{err: err, depth: 1, slot: &root}}
+	nodes := 0
+
+	for len(stack) > 0 {
+		task := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
 
-	if uw, ok := err.(interface{ Unwrap() []error }); ok {
-		children := uw.Unwrap()
+		if task.err == nil {
+			continue
+		}
+
+		// Erax errors are already converted, so they are reused as they are with their meta and children.
+		if e, isErax := asErax(task.err); isErax {
+			*task.slot = e
+			continue
+		}
 
-		errs := make([]error, len(children))
-		for i, child := range children {
-			errs[i] = cast(child)
+		track := isTrackable(task.err)
+		if track {
+			if e, ok := seen[task.err]; ok {
+				*task.slot = e
+				continue
+			}
 		}
 
-		return &errorType{
-			msg:  err.Error(),
-			errs: errs,
+		if (maxCastDepth > 0 && task.depth > maxCastDepth) || (maxCastNodes > 0 && nodes >= maxCastNodes) {
+			*task.slot = &errorType{msg: castTruncatedMarker, origin: task.err, truncated: true}
+			continue
 		}
+		nodes++
+
+		if uw, ok := task.err.(interface{ Unwrap() []error }); ok {
+			children := uw.Unwrap()
+
+			e := &errorType{
+				msg:    joinedOwnMessage(task.err, children),
+				origin: task.err,
+			}
+			if track {
+				remember(&seen, task.err, e)
+			}
+			*task.slot = e
+
+			// Children past the node limit are replaced with a single marker, so a huge join doesn't make one each.
+			shown := len(children)
+			if maxCastNodes > 0 && shown > maxCastNodes-nodes {
+				shown = maxCastNodes - nodes
+			}
+
+			e.errs = make([]error, shown, len(children))
+			if shown < len(children) {
+				e.errs = append(e.errs, &errorType{
+					msg:       castTruncatedMarker,
+					origin:    errors.Join(children[shown:]...),
+					truncated: true,
+				})
+			}
+
+			for i := shown - 1; i >= 0; i-- {
+				stack = append(stack, castTask{err: children[i], depth: task.depth + 1, slot: &e.errs[i]})
+			}
+			continue
+		}
+
+		if uw, ok := task.err.(interface{ Unwrap() error }); ok {
+			child := uw.Unwrap()
+
+			e := &errorType{
+				msg:    ownMessage(task.err, child),
+				origin: task.err,
+			}
+			if track {
+				remember(&seen, task.err, e)
+			}
+			*task.slot = e
+
+			stack = append(stack, castTask{err: child, depth: task.depth + 1, slot: &e.cause})
+			continue
+		}
+
+		*task.slot = task.err
 	}
 
-	if uw, ok := err.(interface{ Unwrap() error }); ok {
-		return &errorType{
-			msg:   err.Error(),
-			cause: cast(uw.Unwrap()),
+	return root
+}
+
+// castTask is an error waiting to be converted by cast, with the place its conversion is stored at.
+type castTask struct {
+	err   error
+	depth int
+	slot  *error
+}
+
+// ownMessage returns the part of the message of err added by err itself, without the message of the error it wraps,
+// so "service: repository: EOF" wrapping "repository: EOF" becomes "service".
+//
+// If err adds nothing, its type is used instead, like "*main.retryError".
+func ownMessage(err, child error) string {
+	msg := err.Error()
+	if child == nil {
+		return msg
+	}
+
+	own, ok := trimChildText(msg, child.Error())
+	if !ok {
+		return msg
+	}
+	if own == "" {
+		return fmt.Sprintf("%T", err)
+	}
+
+	return own
+}
+
+// joinedOwnMessage is ownMessage for errors wrapping several errors,
+// whose message ends with the messages of the wrapped errors on separate lines, like the ones of errors.Join.
+//
+// If err adds nothing, the number of wrapped errors is used instead, like "3 errors".
+func joinedOwnMessage(err error, children []error) string {
+	msg := err.Error()
+	if len(children) == 0 {
+		return msg
+	}
+
+	texts := make([]string, 0, len(children))
+	for _, child := range children {
+		if child != nil {
+			texts = append(texts, child.Error())
 		}
 	}
 
-	return err
+	own, ok := trimChildText(msg, strings.Join(texts, "\n"))
+	if !ok {
+		return msg
+	}
+	if own == "" {
+		return formatCount(len(children)) + " errors"
+	}
+
+	return own
+}
+
+// trimChildText removes the text of the wrapped errors from the end of a message, with the ": " separating them.
+//
+// Reports false if the message doesn't end with the text, or if the text is glued to the rest of the message,
+// like "EOF" at the end of "notEOF".
+func trimChildText(msg, text string) (string, bool) {
+	if text == "" || !strings.HasSuffix(msg, text) {
+		return msg, false
+	}
+
+	prefix := msg[:len(msg)-len(text)]
+	trimmed := strings.TrimRight(prefix, " \t\n")
+	if trimmed == prefix && prefix != "" && !strings.HasSuffix(prefix, ":") {
+		return msg, false
+	}
+
+	return strings.TrimSpace(strings.TrimSuffix(trimmed, ":")), true
+}
+
+// remember records the erax node an error was converted to.
+func remember(seen *map[error]*errorType, err error, e *errorType) {
+	if *seen == nil {
+		*seen = make(map[error]*errorType)
+	}
+	(*seen)[err] = e
 }
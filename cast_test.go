@@ -0,0 +1,37 @@
+package erax
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCastKeepsErrorText(t *testing.T) {
+	chain := fmt.Errorf("service: %w", fmt.Errorf("repository: %w", io.EOF))
+	join := errors.Join(io.EOF, io.ErrUnexpectedEOF)
+
+	for _, err := range []error{chain, join} {
+		if got := Cast(err).Error(); got != err.Error() {
+			t.Errorf("Cast(%q).Error() = %q", err.Error(), got)
+		}
+		if got := fmt.Sprint(Cast(err)); got != err.Error() {
+			t.Errorf("fmt.Sprint(Cast(%q)) = %q", err.Error(), got)
+		}
+	}
+}
+
+func TestCastRendersOwnText(t *testing.T) {
+	err := Cast(fmt.Errorf("service: %w", fmt.Errorf("repository: %w", io.EOF)))
+
+	if got := FormatToJSONMap(err)["message"]; got != "service" {
+		t.Errorf(`JSON message = %q, want "service"`, got)
+	}
+	if got := FormatToJSONString(err); !strings.HasPrefix(got, `{"message":"service",`) {
+		t.Errorf("FormatToJSONString() = %s", got)
+	}
+	if got := FormatCompact(err); strings.Contains(got, "repository: EOF") {
+		t.Errorf("FormatCompact() repeats the wrapped text: %s", got)
+	}
+}
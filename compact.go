@@ -0,0 +1,35 @@
+package erax
+
+import "bytes"
+
+// FormatCompact formats the whole error tree on a single line, for grep-friendly logs.
+//
+// Each error is followed by its metadata in braces, if it has any, and by its children after " <- ".
+// Several children are grouped in brackets and separated by " | ":
+//
+//	service error{code=500} <- failed to load user <- [db timeout | cache miss]
+//
+// Special characters are escaped with a backslash, so the tree can be reconstructed from the line.
+// Errors reachable more than once are labeled like "#1" and referenced later as "(see #1)".
+func FormatCompact(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	var refs nodeRefs
+	if e, isErax := asErax(err); isErax {
+		refs = newNodeRefs(e)
+	}
+
+	writeCompactError(buf, err, &refs)
+	res := buf.String()
+
+	if buf.Cap() <= 16384 {
+		bufferPool.Put(buf)
+	}
+
+	return res
+}
@@ -0,0 +1,175 @@
+package erax
+
+import (
+	"fmt"
+	"strings"
+)
+
+// compactParser reconstructs an error tree from the compact single-line format written by writeCompactError.
+type compactParser struct {
+	s   string
+	pos int
+	ids map[string]*errorType
+}
+
+func (p *compactParser) parse() (error, error) {
+	n, err := p.parseTree()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+
+	return n.build(), nil
+}
+
+// parseTree parses an error followed by its children.
+func (p *compactParser) parseTree() (*parsedNode, error) {
+	n, err := p.parseNode()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.consume(" <- ") {
+		return n, nil
+	}
+
+	if n.ref != nil {
+		return nil, p.errorf("back-reference can't have children")
+	}
+
+	if !p.consume("[") {
+		n.cause, err = p.parseTree()
+		return n, err
+	}
+
+	for {
+		child, err := p.parseTree()
+		if err != nil {
+			return nil, err
+		}
+		n.errs = append(n.errs, child)
+
+		if p.consume(" | ") {
+			continue
+		}
+		if p.consume("]") {
+			return n, nil
+		}
+
+		return nil, p.errorf("expected \" | \" or \"]\"")
+	}
+}
+
+// parseNode parses a single error: a back-reference, or an optional label, the message and the metadata.
+func (p *compactParser) parseNode() (*parsedNode, error) {
+	if label, n := parseBackRef(p.s[p.pos:]); label != "" {
+		ref, ok := p.ids[label]
+		if !ok {
+			return nil, p.errorf("unknown back-reference %q", label)
+		}
+
+		p.pos += n
+		return &parsedNode{ref: ref}, nil
+	}
+
+	n := &parsedNode{e: &errorType{}}
+
+	if label, length := parseLabel(p.s[p.pos:]); label != "" {
+		p.pos += length
+		n.labeled = true
+
+		if p.ids == nil {
+			p.ids = make(map[string]*errorType)
+		}
+		p.ids[label] = n.e
+	}
+
+	n.e.msg = p.readText("{[]", true)
+
+	if !p.consume("{") {
+		return n, nil
+	}
+
+	for {
+		key := p.readText("=", false)
+		if !p.consume("=") {
+			return nil, p.errorf("expected \"=\" after metadata key %q", key)
+		}
+
+		value := p.readText(",}", false)
+		n.e.meta = append(n.e.meta, MetaField{Key: key, Value: value})
+
+		if p.consume(",") {
+			continue
+		}
+		if p.consume("}") {
+			return n, nil
+		}
+
+		return nil, p.errorf("expected \",\" or \"}\"")
+	}
+}
+
+// readText reads escaped text up to the first unescaped stop character.
+//
+// If separators is set, the text also stops at the " <- " and " | " separators.
+func (p *compactParser) readText(stops string, separators bool) string {
+	var sb strings.Builder
+	start := p.pos
+
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+
+		if indexByte(stops, c) != -1 {
+			break
+		}
+
+		if separators && c == ' ' && (strings.HasPrefix(p.s[p.pos:], " <- ") || strings.HasPrefix(p.s[p.pos:], " | ")) {
+			break
+		}
+
+		if c != '\\' || p.pos+1 == len(p.s) {
+			p.pos++
+			continue
+		}
+
+		sb.WriteString(p.s[start:p.pos])
+		switch escaped := p.s[p.pos+1]; escaped {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		default:
+			sb.WriteByte(escaped)
+		}
+
+		p.pos += 2
+		start = p.pos
+	}
+
+	if sb.Len() == 0 {
+		return p.s[start:p.pos]
+	}
+
+	sb.WriteString(p.s[start:p.pos])
+	return sb.String()
+}
+
+// consume skips s if the input continues with it.
+func (p *compactParser) consume(s string) bool {
+	if !strings.HasPrefix(p.s[p.pos:], s) {
+		return false
+	}
+
+	p.pos += len(s)
+	return true
+}
+
+func (p *compactParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("erax: invalid compact trace at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,107 @@
+package erax
+
+import "bytes"
+
+// Characters escaped in the compact format, depending on where they appear.
+const (
+	compactMessageSpecials = `\{[]|<#`
+	compactKeySpecials     = `\=,}`
+	compactValueSpecials   = `\,}`
+)
+
+// writeCompactError writes an error and its children in the compact single-line format.
+func writeCompactError(buf *bytes.Buffer, err error, refs *nodeRefs) {
+	e, isErax := asErax(err)
+	if !isErax {
+		writeCompactEscaped(buf, err.Error(), compactMessageSpecials)
+		return
+	}
+
+	if ref := refs.seen(e); ref != nil {
+		buf.WriteString(ref.backRef())
+		return
+	}
+
+	if ref := refs.enter(e); ref != nil {
+		defer refs.leave(ref)
+		buf.WriteString(ref.id)
+		buf.WriteByte(' ')
+	}
+
+	writeCompactEscaped(buf, e.msg, compactMessageSpecials)
+
+	if len(e.meta) > 0 {
+		buf.WriteByte('{')
+		for i, field := range e.meta {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCompactEscaped(buf, field.Key, compactKeySpecials)
+			buf.WriteByte('=')
+			writeCompactEscaped(buf, field.Value, compactValueSpecials)
+		}
+		buf.WriteByte('}')
+	}
+
+	childrenLen := len(e.errs)
+	if e.cause != nil {
+		childrenLen++
+	}
+
+	if childrenLen == 0 {
+		return
+	}
+
+	buf.WriteString(" <- ")
+
+	if childrenLen == 1 {
+		if e.cause != nil {
+			writeCompactError(buf, e.cause, refs)
+		} else {
+			writeCompactError(buf, e.errs[0], refs)
+		}
+		return
+	}
+
+	buf.WriteByte('[')
+	for i, ue := range e.errs {
+		if i > 0 {
+			buf.WriteString(" | ")
+		}
+		writeCompactError(buf, ue, refs)
+	}
+	if e.cause != nil {
+		buf.WriteString(" | ")
+		writeCompactError(buf, e.cause, refs)
+	}
+	buf.WriteByte(']')
+}
+
+// writeCompactEscaped writes a string with the special characters and line breaks escaped with a backslash.
+func writeCompactEscaped(buf *bytes.Buffer, s, specials string) {
+	last := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		var escaped byte
+		switch c {
+		case '\n':
+			escaped = 'n'
+		case '\r':
+			escaped = 'r'
+		case '\t':
+			escaped = 't'
+		default:
+			if indexByte(specials, c) == -1 {
+				continue
+			}
+			escaped = c
+		}
+
+		buf.WriteString(s[last:i])
+		buf.WriteByte('\\')
+		buf.WriteByte(escaped)
+		last = i + 1
+	}
+	buf.WriteString(s[last:])
+}
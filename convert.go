@@ -2,11 +2,52 @@ package erax
 
 import "errors"
 
-func errorToMap(err *errorType) map[string]any {
+// toJSONMap converts an error at the given depth of the tree to its JSON map representation.
+//
+// Erax nodes reachable more than once are converted only once, labeled with an "anchor",
+// and later occurrences are converted to a "ref" to it.
+// If node IDs are enabled, id is the path ID of the error, and every error gets it as "id".
+func toJSONMap(err error, depth int, refs *nodeRefs, id string) map[string]any {
+	if err == nil {
+		return nil
+	}
+
+	if next, isErax := asErax(err); isErax {
+		return errorToMap(next, depth, refs, id)
+	}
+
+	m := map[string]any{
+		"message": err.Error(),
+	}
+	if id != "" {
+		m["id"] = "#" + id
+	}
+
+	return m
+}
+
+func errorToMap(err *errorType, depth int, refs *nodeRefs, id string) map[string]any {
 	m := map[string]any{
 		"message": err.msg,
 	}
 
+	if id != "" {
+		m["id"] = "#" + id
+	}
+
+	if ref := refs.seen(err); ref != nil {
+		m["ref"] = ref.id
+		return m
+	}
+
+	ref := refs.enterAt(err, id)
+	defer refs.leave(ref)
+
+	// Only nodes reachable more than once are labeled, so mapToError can tell them from the others.
+	if ref != nil {
+		m["anchor"] = ref.id
+	}
+
 	if len(err.meta) > 0 {
 		m["meta"] = err.meta
 	}
@@ -18,20 +59,37 @@ func errorToMap(err *errorType) map[string]any {
 		return m
 	}
 
+	if maxDepth > 0 && depth >= maxDepth {
+		m["cause"] = map[string]any{
+			"message": deeperLevelsMarker(treeHeight(err) - 1),
+		}
+		return m
+	}
+
+	shown, hidden := limitChildren(len(err.errs))
+
 	if hasCause && !hasErrs {
-		m["cause"] = FormatToJSONMap(err.cause)
+		m["cause"] = toJSONMap(err.cause, depth+1, refs, causeNodeID(id))
 	} else {
-		totalLen := len(err.errs)
+		totalLen := shown
 		if hasCause {
 			totalLen++
 		}
+		if hidden > 0 {
+			totalLen++
+		}
 
 		causeSlice := make([]map[string]any, 0, totalLen)
 		if hasCause {
-			causeSlice = append(causeSlice, FormatToJSONMap(err.cause))
+			causeSlice = append(causeSlice, toJSONMap(err.cause, depth+1, refs, causeNodeID(id)))
 		}
-		for _, ue := range err.errs {
-			causeSlice = append(causeSlice, FormatToJSONMap(ue))
+		for i, ue := range err.errs[:shown] {
+			causeSlice = append(causeSlice, toJSONMap(ue, depth+1, refs, childNodeID(id, i)))
+		}
+		if hidden > 0 {
+			causeSlice = append(causeSlice, map[string]any{
+				"message": moreErrorsMarker(hidden),
+			})
 		}
 		m["cause"] = causeSlice
 	}
@@ -39,16 +97,26 @@ func errorToMap(err *errorType) map[string]any {
 	return m
 }
 
-func mapToError(m map[string]any) error {
+// nodeIDs maps the labels of deserialized erax nodes to the nodes, so back-references can be resolved.
+type nodeIDs map[string]*errorType
+
+func mapToError(m map[string]any, ids *nodeIDs) error {
 	msg, msgOk := m["message"].(string)
 	if !msgOk {
 		return nil
 	}
 
+	if ref, ok := m["ref"].(string); ok {
+		if e, ok := (*ids)[ref]; ok {
+			return e
+		}
+	}
+
 	meta, metaOk := m["meta"].([]MetaField)
 	cause, causeOk := m["cause"]
+	anchor, anchorOk := m["anchor"].(string)
 
-	if (meta == nil || !metaOk) && !causeOk {
+	if (meta == nil || !metaOk) && !causeOk && !anchorOk {
 		return errors.New(msg)
 	}
 
@@ -56,19 +124,26 @@ func mapToError(m map[string]any) error {
 		msg: msg,
 	}
 
+	if anchorOk {
+		if *ids == nil {
+			*ids = make(nodeIDs)
+		}
+		(*ids)[anchor] = err
+	}
+
 	if meta != nil && metaOk {
 		err.meta = meta
 	}
 
 	if causeOk {
 		if value, ok := cause.(map[string]any); ok {
-			if childErr := mapToError(value); childErr != nil {
+			if childErr := mapToError(value, ids); childErr != nil {
 				err.cause = childErr
 			}
 		} else if value, ok := cause.([]map[string]any); ok {
 			err.errs = make([]error, len(value))
 			for i, c := range value {
-				err.errs[i] = mapToError(c)
+				err.errs[i] = mapToError(c, ids)
 			}
 		}
 	}
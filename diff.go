@@ -0,0 +1,335 @@
+package erax
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is the kind of a Change between two error trees.
+type ChangeKind int
+
+const (
+	// NodeAdded is a node only present in the new tree, with everything below it.
+	NodeAdded ChangeKind = iota
+	// NodeRemoved is a node only present in the old tree, with everything below it.
+	NodeRemoved
+	// MessageChanged is a node present in both trees, with a different message.
+	MessageChanged
+	// MetaAdded is a metadata field only present in the new node.
+	MetaAdded
+	// MetaRemoved is a metadata field only present in the old node.
+	MetaRemoved
+	// MetaChanged is a metadata field present in both nodes, with a different value.
+	MetaChanged
+)
+
+// Change is a single difference between two error trees.
+type Change struct {
+	Kind ChangeKind
+	// Path is the path of the node in the new tree, or in the old one for removed nodes,
+	// the same way as for Walk.
+	Path []int
+	// Key is the metadata key for metadata changes.
+	Key string
+	// Old and New are the old and new message or metadata value. Old is empty for additions, New for removals.
+	Old, New string
+}
+
+// String describes the change on a single line, for test failures.
+func (c Change) String() string {
+	path := formatPath(c.Path)
+
+	switch c.Kind {
+	case NodeAdded:
+		return fmt.Sprintf("%s: added %q", path, c.New)
+	case NodeRemoved:
+		return fmt.Sprintf("%s: removed %q", path, c.Old)
+	case MessageChanged:
+		return fmt.Sprintf("%s: message %q → %q", path, c.Old, c.New)
+	case MetaAdded:
+		return fmt.Sprintf("%s: added meta %s=%q", path, c.Key, c.New)
+	case MetaRemoved:
+		return fmt.Sprintf("%s: removed meta %s=%q", path, c.Key, c.Old)
+	}
+
+	return fmt.Sprintf("%s: meta %s %q → %q", path, c.Key, c.Old, c.New)
+}
+
+// TreeDiff is the result of Diff: the changes between two error trees.
+type TreeDiff struct {
+	// Changes holds the changes in the order of the nodes in the trees.
+	Changes []Change
+
+	lines []diffLine
+}
+
+// Diff compares an old (expected) error tree with a new (actual) one.
+//
+// Nodes are matched by their message among the children of matched parents, the same way a text diff matches lines,
+// so an inserted error doesn't turn all its siblings into changes. Unmatched nodes at the same place are compared
+// to each other as changed ones. Children are the errors of a node followed by its cause, the same way as for Walk,
+// and non-erax errors are compared through the errors they unwrap to.
+func Diff(a, b error) *TreeDiff {
+	d := &TreeDiff{}
+	differ := differ{d: d, seen: make(map[[2]any]bool)}
+
+	switch {
+	case a == nil && b == nil:
+	case a == nil:
+		differ.added(Inspect(b), nil, 0)
+	case b == nil:
+		differ.removed(Inspect(a), nil, 0)
+	default:
+		differ.node(Inspect(a), Inspect(b), nil, 0)
+	}
+
+	return d
+}
+
+// Equal reports whether the trees are the same.
+func (d *TreeDiff) Equal() bool {
+	return len(d.Changes) == 0
+}
+
+// String renders both trees merged into one, colored with the current theme:
+// removed parts are prefixed with "-", added ones with "+", and changed ones with "~".
+// Returns an empty string if the trees are the same.
+func (d *TreeDiff) String() string {
+	if d.Equal() {
+		return ""
+	}
+
+	var sb strings.Builder
+	writeDiff(&sb, d.lines)
+
+	return sb.String()
+}
+
+// differ holds the state of a Diff call.
+type differ struct {
+	d *TreeDiff
+	// seen holds the pairs of errors already compared, so shared errors and cycles are compared once.
+	seen map[[2]any]bool
+}
+
+func (df *differ) node(a, b Node, path []int, depth int) {
+	if key, ok := diffPairKey(a, b); ok {
+		if df.seen[key] {
+			df.line(diffSame, depth, b.Message(), "")
+			return
+		}
+		df.seen[key] = true
+	}
+
+	if a.Message() != b.Message() {
+		df.change(Change{Kind: MessageChanged, Path: path, Old: a.Message(), New: b.Message()})
+		df.line(diffChanged, depth, a.Message(), b.Message())
+	} else {
+		df.line(diffSame, depth, b.Message(), "")
+	}
+
+	df.meta(a.Meta(), b.Meta(), path, depth)
+	df.children(diffChildren(a), diffChildren(b), path, depth)
+}
+
+// meta compares the metadata of two matched nodes. Keys are compared by their last value, the same way GetMeta reads them.
+func (df *differ) meta(a, b []MetaField, path []int, depth int) {
+	for _, field := range lastValues(a) {
+		value, ok := lastValue(b, field.Key)
+		switch {
+		case !ok:
+			df.change(Change{Kind: MetaRemoved, Path: path, Key: field.Key, Old: field.Value})
+			df.line(diffRemovedMeta, depth, field.Key+": "+field.Value, "")
+		case value != field.Value:
+			df.change(Change{Kind: MetaChanged, Path: path, Key: field.Key, Old: field.Value, New: value})
+			df.line(diffChangedMeta, depth, field.Key+": "+field.Value, value)
+		default:
+			df.line(diffSameMeta, depth, field.Key+": "+field.Value, "")
+		}
+	}
+
+	for _, field := range lastValues(b) {
+		if _, ok := lastValue(a, field.Key); !ok {
+			df.change(Change{Kind: MetaAdded, Path: path, Key: field.Key, New: field.Value})
+			df.line(diffAddedMeta, depth, field.Key+": "+field.Value, "")
+		}
+	}
+}
+
+// children matches the children of two matched nodes by their messages with a longest common subsequence.
+func (df *differ) children(a, b []Node, path []int, depth int) {
+	pairs := matchByMessage(a, b)
+
+	i, j := 0, 0
+	for _, pair := range append(pairs, [2]int{len(a), len(b)}) {
+		// Unmatched children between two matches are compared to each other, the rest are removed or added.
+		for i < pair[0] && j < pair[1] {
+			df.node(a[i], b[j], childPath(path, j), depth+1)
+			i++
+			j++
+		}
+		for ; i < pair[0]; i++ {
+			df.removed(a[i], childPath(path, i), depth+1)
+		}
+		for ; j < pair[1]; j++ {
+			df.added(b[j], childPath(path, j), depth+1)
+		}
+
+		if pair[0] < len(a) {
+			df.node(a[i], b[j], childPath(path, j), depth+1)
+			i++
+			j++
+		}
+	}
+}
+
+// removed reports a node of the old tree without a match, with everything below it.
+func (df *differ) removed(n Node, path []int, depth int) {
+	df.change(Change{Kind: NodeRemoved, Path: path, Old: n.Message()})
+	df.subtree(n, diffRemoved, diffRemovedMeta, depth)
+}
+
+// added reports a node of the new tree without a match, with everything below it.
+func (df *differ) added(n Node, path []int, depth int) {
+	df.change(Change{Kind: NodeAdded, Path: path, New: n.Message()})
+	df.subtree(n, diffAdded, diffAddedMeta, depth)
+}
+
+// subtree renders a node with everything below it as removed or added, without recursion.
+func (df *differ) subtree(n Node, kind, metaKind diffLineKind, depth int) {
+	var seen visitSet
+
+	type item struct {
+		n     Node
+		depth int
+	}
+	stack := []item{{n, depth}}
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if err := current.n.Err(); err != nil && !seen.add(err) {
+			continue
+		}
+
+		df.line(kind, current.depth, current.n.Message(), "")
+		for _, field := range current.n.Meta() {
+			df.line(metaKind, current.depth, field.Key+": "+field.Value, "")
+		}
+
+		children := diffChildren(current.n)
+		for i := len(children) - 1; i >= 0; i-- {
+			stack = append(stack, item{children[i], current.depth + 1})
+		}
+	}
+}
+
+func (df *differ) change(c Change) {
+	df.d.Changes = append(df.d.Changes, c)
+}
+
+func (df *differ) line(kind diffLineKind, depth int, text, newText string) {
+	df.d.lines = append(df.d.lines, diffLine{kind: kind, depth: depth, text: text, newText: newText})
+}
+
+// diffChildren returns the children of a node: its errors, then its cause.
+func diffChildren(n Node) []Node {
+	children := n.Errors()
+	if cause := n.Cause(); cause != nil {
+		children = append(children, cause)
+	}
+	return children
+}
+
+// diffPairKey returns the key of a pair of compared errors, if both of them can be tracked.
+func diffPairKey(a, b Node) ([2]any, bool) {
+	ea, eb := a.Err(), b.Err()
+	if !isTrackable(ea) || !isTrackable(eb) {
+		return [2]any{}, false
+	}
+	return [2]any{ea, eb}, true
+}
+
+// matchByMessage returns the indexes of the children matched by the longest common subsequence of their messages.
+func matchByMessage(a, b []Node) [][2]int {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+
+	// lengths[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i].Message() == b[j].Message() {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	var pairs [][2]int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i].Message() == b[j].Message():
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return pairs
+}
+
+// lastValues returns the metadata fields with the last value of every key, in the order the keys first appear.
+func lastValues(meta []MetaField) []MetaField {
+	var fields []MetaField
+	for _, field := range meta {
+		if _, ok := lastValue(fields, field.Key); ok {
+			continue
+		}
+		value, _ := lastValue(meta, field.Key)
+		fields = append(fields, MetaField{Key: field.Key, Value: value})
+	}
+	return fields
+}
+
+func lastValue(meta []MetaField, key string) (string, bool) {
+	for i := len(meta) - 1; i >= 0; i-- {
+		if meta[i].Key == key {
+			return meta[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// formatPath formats a path like "[0 2 1]", or "root" for the root.
+func formatPath(path []int) string {
+	if len(path) == 0 {
+		return "root"
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, idx := range path {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(strconv.Itoa(idx))
+	}
+	sb.WriteByte(']')
+
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package erax
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+type diffLineKind int
+
+const (
+	diffSame diffLineKind = iota
+	diffAdded
+	diffRemoved
+	diffChanged
+	diffSameMeta
+	diffAddedMeta
+	diffRemovedMeta
+	diffChangedMeta
+)
+
+// diffLine is a line of the rendered diff: a node or a metadata field of a node at the given depth.
+type diffLine struct {
+	kind  diffLineKind
+	depth int
+	text  string
+	// newText is the new message or value of changed lines.
+	newText string
+}
+
+// writeDiff renders the lines of a diff, indenting nodes by their depth and metadata one more level.
+func writeDiff(sb *strings.Builder, lines []diffLine) {
+	for _, line := range lines {
+		var marker string
+		switch line.kind {
+		case diffAdded, diffAddedMeta:
+			marker = "+ "
+		case diffRemoved, diffRemovedMeta:
+			marker = "- "
+		case diffChanged, diffChangedMeta:
+			marker = "~ "
+		default:
+			marker = "  "
+		}
+
+		depth := line.depth
+		isMeta := line.kind >= diffSameMeta
+		if isMeta {
+			depth++
+		}
+
+		// Changed lines hold both versions, and the key of a changed field is only rendered once: "key: old → new".
+		text := line.text
+		if line.kind == diffChanged || line.kind == diffChangedMeta {
+			text += " → " + line.newText
+		}
+		text = strings.ReplaceAll(text, "\n", "\n"+strings.Repeat("  ", depth+1))
+
+		sb.WriteString(diffStyle(line.kind).Render(marker + strings.Repeat("  ", depth) + text))
+		sb.WriteByte('\n')
+	}
+}
+
+// diffStyle returns the style of a line of a diff: the value color for additions, the error color for removals,
+// the key color for changes, and the branch color for unchanged lines.
+func diffStyle(kind diffLineKind) lipgloss.Style {
+	switch kind {
+	case diffAdded, diffAddedMeta:
+		return valueText
+	case diffRemoved, diffRemovedMeta:
+		return errorText
+	case diffChanged, diffChangedMeta:
+		return keyText
+	}
+	return elisionText
+}
@@ -0,0 +1,78 @@
+// Package eraxtest provides test assertions for erax error trees.
+//
+// Assertions read error trees the same way the erax package does in production,
+// and report failures with t.Errorf, so a test keeps going and shows every failed assertion.
+// Every assertion returns whether it passed.
+package eraxtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DangeL187/erax"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// AssertMeta checks that the metadata field key of err is want.
+//
+// The field is looked up with erax.GetMeta, so the most recent value anywhere in the tree wins.
+func AssertMeta(t testing.TB, err error, key, want string) bool {
+	t.Helper()
+
+	got, ok := erax.GetMeta(err, key)
+	if !ok {
+		t.Errorf("eraxtest: metadata %q not found, want %q in:\n%s", key, want, plainTrace(err))
+		return false
+	}
+
+	if got != want {
+		t.Errorf("eraxtest: metadata %q is %q, want %q in:\n%s", key, got, want, plainTrace(err))
+		return false
+	}
+
+	return true
+}
+
+// AssertCode checks that the "code" metadata field of err is want.
+func AssertCode(t testing.TB, err error, want string) bool {
+	t.Helper()
+
+	return AssertMeta(t, err, "code", want)
+}
+
+// AssertTreeShape checks that err has the structure and messages described by spec, written in the compact format:
+//
+//	service error{code=500} <- failed to load user <- [db timeout | cache miss]
+//
+// Only the metadata fields written in spec are checked, so the tree may carry more of them.
+// The differences are reported with erax.Diff.
+func AssertTreeShape(t testing.TB, err error, spec string) bool {
+	t.Helper()
+
+	want, parseErr := erax.ParseCompact(spec)
+	if parseErr != nil {
+		t.Fatalf("eraxtest: invalid tree shape: %v", parseErr)
+		return false
+	}
+
+	diff := erax.Diff(want, err)
+
+	var changes []string
+	for _, c := range diff.Changes {
+		if c.Kind != erax.MetaAdded {
+			changes = append(changes, c.String())
+		}
+	}
+
+	if len(changes) == 0 {
+		return true
+	}
+
+	t.Errorf("eraxtest: tree shape mismatch:\n%s\n\n%s", strings.Join(changes, "\n"), ansi.Strip(diff.String()))
+	return false
+}
+
+// plainTrace returns the uncolored trace of err.
+func plainTrace(err error) string {
+	return ansi.Strip(erax.Format(err))
+}
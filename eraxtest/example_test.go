@@ -0,0 +1,156 @@
+package eraxtest_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DangeL187/erax"
+	"github.com/DangeL187/erax/eraxtest"
+)
+
+// recorder is a testing.TB that records failures instead of failing, so the examples can print them.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func newError() error {
+	err := erax.WrapWithErrors(nil, "failed to load user", erax.New("db timeout"), erax.New("cache miss"))
+	return erax.WithMeta(err, "service error", erax.F("code", "500"))
+}
+
+func ExampleAssertCode() {
+	t := &recorder{}
+
+	fmt.Println(eraxtest.AssertCode(t, newError(), "500"))
+	fmt.Println(eraxtest.AssertCode(t, newError(), "404"))
+	fmt.Println(t.failures[0])
+
+	// Output:
+	// true
+	// false
+	// eraxtest: metadata "code" is "500", want "404" in:
+	//  ▼ [ERROR TRACE]
+	//  ├── service error
+	//  │    ╰─ code: 500
+	//  ╰── failed to load user
+	//       ├── db timeout
+	//       ╰── cache miss
+}
+
+func ExampleAssertMeta() {
+	t := &recorder{}
+
+	fmt.Println(eraxtest.AssertMeta(t, newError(), "user", "42"))
+	fmt.Println(t.failures[0])
+
+	// Output:
+	// false
+	// eraxtest: metadata "user" not found, want "42" in:
+	//  ▼ [ERROR TRACE]
+	//  ├── service error
+	//  │    ╰─ code: 500
+	//  ╰── failed to load user
+	//       ├── db timeout
+	//       ╰── cache miss
+}
+
+func ExampleAssertTreeShape() {
+	t := &recorder{}
+
+	fmt.Println(eraxtest.AssertTreeShape(t, newError(), "service error <- failed to load user <- [db timeout | cache miss]"))
+	fmt.Println(eraxtest.AssertTreeShape(t, newError(), "service error{code=503} <- failed to load user <- [db timeout]"))
+	fmt.Println(t.failures[0])
+
+	// Output:
+	// true
+	// false
+	// eraxtest: tree shape mismatch:
+	// root: meta code "503" → "500"
+	// [0 1]: added "cache miss"
+	//
+	//   service error
+	// ~   code: 503 → 500
+	//     failed to load user
+	//       db timeout
+	// +     cache miss
+}
+
+func ExampleAssertGolden() {
+	dir, err := os.MkdirTemp("", "eraxtest")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "load_user.trace")
+	t := &recorder{}
+
+	// -eraxtest.update writes the golden file instead of checking it.
+	_ = flag.Set("eraxtest.update", "true")
+	fmt.Println(eraxtest.AssertGolden(t, newError(), path))
+	_ = flag.Set("eraxtest.update", "false")
+
+	data, _ := os.ReadFile(path)
+	fmt.Print(string(data))
+
+	fmt.Println(eraxtest.AssertGolden(t, newError(), path))
+
+	changed := erax.WrapWithErrors(nil, "failed to load user", erax.New("db unavailable"), erax.New("cache miss"))
+	changed = erax.WithMeta(changed, "service error", erax.F("code", "500"))
+
+	fmt.Println(eraxtest.AssertGolden(t, changed, path))
+	fmt.Println(strings.ReplaceAll(t.failures[0], dir, "testdata"))
+
+	// Output:
+	// true
+	//  ▼ [ERROR TRACE]
+	//  ├── service error
+	//  │    ╰─ code: 500
+	//  ╰── failed to load user
+	//       ├── db timeout
+	//       ╰── cache miss
+	//
+	// {
+	//   "message": "service error",
+	//   "meta": {
+	//     "code": "500"
+	//   },
+	//   "cause": {
+	//     "message": "failed to load user",
+	//     "cause": [
+	//       {
+	//         "message": "db timeout"
+	//       },
+	//       {
+	//         "message": "cache miss"
+	//       }
+	//     ]
+	//   }
+	// }
+	// true
+	// false
+	// eraxtest: testdata/load_user.trace doesn't match:
+	// [0 0]: message "db timeout" → "db unavailable"
+	//
+	//   service error
+	//     code: 500
+	//     failed to load user
+	// ~     db timeout → db unavailable
+	//       cache miss
+}
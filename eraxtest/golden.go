@@ -0,0 +1,84 @@
+package eraxtest
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DangeL187/erax"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// update is namespaced, so it doesn't clash with an -update flag defined by the test package itself.
+var update = flag.Bool("eraxtest.update", false, "update the golden files of eraxtest.AssertGolden")
+
+// goldenSeparator separates the trace from the JSON in a golden file, as written by erax.FormatSnapshot.
+const goldenSeparator = "\n\n"
+
+// AssertGolden checks that err matches the golden file at path.
+//
+// The golden file holds the uncolored trace of err followed by its indented JSON, so changes are easy to review.
+// It is rendered by erax.FormatSnapshot with the default settings, so the width of the terminal,
+// SetAlignMeta, the limits and SetNodeIDs don't change it, and the same test passes locally and in CI.
+// Run the tests with -eraxtest.update to write the golden files instead of checking them:
+//
+//	go test ./... -eraxtest.update
+//
+// Mismatches are reported with erax.Diff against the tree parsed back from the trace of the golden file.
+func AssertGolden(t testing.TB, err error, path string) bool {
+	t.Helper()
+
+	got := erax.FormatSnapshot(err)
+
+	if *update {
+		if mkErr := os.MkdirAll(filepath.Dir(path), 0o755); mkErr != nil {
+			t.Fatalf("eraxtest: can't create golden file directory: %v", mkErr)
+			return false
+		}
+		if writeErr := os.WriteFile(path, []byte(got), 0o644); writeErr != nil {
+			t.Fatalf("eraxtest: can't write golden file: %v", writeErr)
+			return false
+		}
+		return true
+	}
+
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		t.Fatalf("eraxtest: can't read golden file (run with -eraxtest.update to create it): %v", readErr)
+		return false
+	}
+
+	want := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if got == want {
+		return true
+	}
+
+	if expected, parseErr := parseGolden(want); parseErr == nil {
+		if diff := erax.Diff(expected, err); !diff.Equal() {
+			changes := make([]string, len(diff.Changes))
+			for i, c := range diff.Changes {
+				changes[i] = c.String()
+			}
+
+			t.Errorf("eraxtest: %s doesn't match:\n%s\n\n%s", path, strings.Join(changes, "\n"), ansi.Strip(diff.String()))
+			return false
+		}
+	}
+
+	// The trees are the same, but the rendering changed.
+	t.Errorf("eraxtest: %s doesn't match:\n--- want\n%s\n--- got\n%s", path, want, got)
+	return false
+}
+
+// parseGolden rebuilds the error tree from the trace part of a golden file.
+func parseGolden(text string) (error, error) {
+	idx := strings.LastIndex(text, goldenSeparator+"{")
+	if idx == -1 {
+		return nil, errors.New("eraxtest: no JSON in golden file")
+	}
+
+	return erax.ParseTrace(text[:idx])
+}
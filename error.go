@@ -4,7 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"strings"
+	"reflect"
 )
 
 type errorType struct {
@@ -12,6 +12,12 @@ type errorType struct {
 	errs  []error
 	meta  []MetaField
 	msg   string
+
+	// origin is the non-erax error this node was cast from, so errors.As and errors.Is still reach it.
+	origin error
+	// truncated is set for a marker standing in for the errors Cast left out.
+	// Its origin holds all of them, and errors.As and errors.Is search their whole trees.
+	truncated bool
 }
 
 // Unwrap returns the child errors of this error.
@@ -30,8 +36,72 @@ func (e *errorType) Unwrap() []error {
 	return nil
 }
 
+// As reports whether the non-erax error this node was cast from matches target, and sets target to it if so.
+//
+// This implements the errors.As interface. The errors wrapped by the original error are not searched,
+// since they are cast too, and reachable through Unwrap. Truncation markers search them,
+// since they weren't cast, so truncation doesn't change what errors.As finds.
+func (e *errorType) As(target any) bool {
+	if e.origin == nil {
+		return false
+	}
+
+	if e.truncated {
+		return errors.As(e.origin, target)
+	}
+
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Pointer || val.IsNil() {
+		return false
+	}
+
+	if typ := val.Type().Elem(); reflect.TypeOf(e.origin).AssignableTo(typ) {
+		val.Elem().Set(reflect.ValueOf(e.origin))
+		return true
+	}
+
+	if x, ok := e.origin.(interface{ As(any) bool }); ok {
+		return x.As(target)
+	}
+
+	return false
+}
+
+// Is reports whether the non-erax error this node was cast from is target.
+//
+// This implements the errors.Is interface. Like As, it only searches the errors wrapped by the original error
+// for truncation markers.
+func (e *errorType) Is(target error) bool {
+	if e.origin == nil {
+		return false
+	}
+
+	if e.truncated {
+		return errors.Is(e.origin, target)
+	}
+
+	if target != nil && reflect.TypeOf(target).Comparable() && e.origin == target {
+		return true
+	}
+
+	if x, ok := e.origin.(interface{ Is(error) bool }); ok {
+		return x.Is(target)
+	}
+
+	return false
+}
+
 // Error returns the error message string.
-func (e *errorType) Error() string { return e.msg }
+//
+// Errors converted by Cast return the message of the original error, with the messages of the errors it wraps,
+// so logs and comparisons see the same text as before the conversion. Only the renderers show the part of it
+// added by the error itself.
+func (e *errorType) Error() string {
+	if e.origin != nil {
+		return e.origin.Error()
+	}
+	return e.msg
+}
 
 // Format implements fmt.Formatter for custom formatting.
 //
@@ -40,12 +110,7 @@ func (e *errorType) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			var sb strings.Builder
-			sb.Grow(512)
-			sb.WriteString(message)
-			sb.WriteByte('\n')
-			formatErrorChain(&sb, e, false, nil)
-			_, _ = io.WriteString(s, sb.String())
+			_, _ = WriteTrace(s, e)
 			return
 		}
 		fallthrough
@@ -56,6 +121,13 @@ func (e *errorType) Format(s fmt.State, verb rune) {
 	}
 }
 
+// WriteTo writes the error trace to w.
+//
+// This implements io.WriterTo, streaming the trace the same way WriteTrace does.
+func (e *errorType) WriteTo(w io.Writer) (int64, error) {
+	return WriteTrace(w, e)
+}
+
 // New creates a new standard Go error with the given message.
 func New(message string) error {
 	return errors.New(message)
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
 
 	"github.com/DangeL187/erax"
 	pkgerrors "github.com/pkg/errors"
@@ -35,9 +37,43 @@ func castShowcase() {
 
 	err = erax.Cast(err)
 
+	// Every node only keeps its own part of the message,
+	// so the trace shows "service", "repository" and "EOF" instead of repeating the same text.
 	fmt.Println(erax.Format(err))
 }
 
+func castAsShowcase() {
+	_, err := os.Open("/does/not/exist")
+	err = erax.WrapCast(err, "failed to load config")
+
+	// Cast errors keep the original ones,
+	// so errors.As and errors.Is still reach types like *fs.PathError.
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		fmt.Println("As matched path:", pathErr.Path)
+	}
+	fmt.Println("Is ErrNotExist:", errors.Is(err, fs.ErrNotExist))
+
+	// Format still gets the structured tree.
+	fmt.Println(erax.Format(err))
+}
+
+func castLimitsShowcase() {
+	// Cast doesn't use recursion, so even very deep foreign chains are safe to convert.
+	//
+	// SetMaxCastDepth and SetMaxCastNodes bound how much of them is converted,
+	// and the rest is replaced with a "… truncated" marker.
+	erax.SetMaxCastDepth(3)
+	defer erax.SetMaxCastDepth(0)
+
+	var err error = io.EOF
+	for i := 0; i < 10000; i++ {
+		err = fmt.Errorf("middleware %d: %w", i, err)
+	}
+
+	fmt.Println(erax.Format(erax.Cast(err)))
+}
+
 func isAsShowcase() {
 	base := erax.New("database error")
 
@@ -72,6 +108,18 @@ func main() {
 	fmt.Println("=============================")
 	fmt.Println()
 
+	castAsShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	castLimitsShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
 	isAsShowcase()
 
 	fmt.Println()
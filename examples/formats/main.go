@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/DangeL187/erax"
+)
+
+func newError() error {
+	err := erax.WrapWithErrors(
+		nil,
+		"failed to load user",
+		erax.New("db timeout"),
+		erax.New("cache miss"),
+	)
+
+	return erax.WithMeta(err, "service error", erax.F("code", "500"))
+}
+
+func formatCompactShowcase() {
+	// FormatCompact puts the whole error tree on a single line.
+	//
+	// Useful for grep-friendly logs:
+	//   service error{code=500} <- failed to load user <- [db timeout | cache miss]
+	fmt.Println(erax.FormatCompact(newError()))
+}
+
+func formatMarkdownShowcase() {
+	// FormatMarkdown renders the error tree as nested Markdown lists,
+	// ready to be pasted into incident docs and PR comments:
+	//   - service error `code=500`
+	//   - failed to load user
+	//     - db timeout
+	//     - cache miss
+	fmt.Print(erax.FormatMarkdown(newError()))
+}
+
+func formatHTMLShowcase() {
+	// FormatHTML renders the error tree as self-contained HTML for web pages.
+	//
+	// Nested errors are collapsible, and colors come from "erax-" CSS classes,
+	// so the page can restyle them.
+	fmt.Print(erax.FormatHTML(newError()))
+}
+
+func formatInvertedShowcase() {
+	// FormatInverted starts from the root causes instead of the outermost error.
+	//
+	// Every leaf is followed by the errors that wrapped it, keeping their metadata.
+	fmt.Println(erax.FormatInverted(newError()))
+
+	// RootCauses returns the leaves themselves.
+	for _, cause := range erax.RootCauses(newError()) {
+		fmt.Println("root cause:", cause)
+	}
+}
+
+func graphShowcase() {
+	// ToDOT and ToMermaid render the error tree as a diagram.
+	//
+	// Cause edges and edges to the errors of WrapWithErrors nodes are drawn differently,
+	// and non-erax errors are styled apart from erax errors.
+	fmt.Print(erax.ToDOT(newError()))
+	fmt.Println()
+	fmt.Print(erax.ToMermaid(newError()))
+}
+
+func ciShowcase() {
+	err := erax.WithMeta(newError(), "", erax.F("file", "user/service.go"), erax.F("line", "42"))
+
+	// FormatGitHubAnnotations turns every root cause into a GitHub Actions annotation.
+	// The location comes from "file" and "line" metadata.
+	fmt.Print(erax.FormatGitHubAnnotations(err))
+
+	// FormatProblems is the same for CI systems with "file:line: error: message" problem matchers.
+	fmt.Print(erax.FormatProblems(err))
+}
+
+func nodeIDsShowcase() {
+	// SetNodeIDs prefixes every error with a stable path ID,
+	// so "#2.1.1" can be quoted in a ticket instead of "the first child of the second error".
+	//
+	// The same IDs appear as "id" in JSON.
+	erax.SetNodeIDs(true)
+	defer erax.SetNodeIDs(false)
+
+	err := newError()
+	fmt.Println(erax.Format(err))
+
+	// NodeAt retrieves the error at an ID.
+	fmt.Println("#2.2.1:", erax.NodeAt(err, "#2.2.1"))
+}
+
+func parseShowcase() {
+	// Parse rebuilds an error tree from a rendered trace,
+	// either from Format output or from FormatCompact output.
+	//
+	// Colors are stripped automatically, so traces can be copied straight from old logs.
+	err, parseErr := erax.Parse(erax.Format(newError()))
+	if parseErr != nil {
+		fmt.Println("parse failed:", parseErr)
+		return
+	}
+
+	code, _ := erax.GetMeta(err, "code")
+	fmt.Println("code:", code)
+
+	fmt.Println(erax.FormatToJSONString(err))
+}
+
+func main() {
+	fmt.Println()
+
+	formatCompactShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	formatMarkdownShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	formatHTMLShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	formatInvertedShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	graphShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	ciShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	nodeIDsShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	parseShowcase()
+
+	fmt.Println()
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DangeL187/erax"
+)
+
+func newError() error {
+	err := erax.WrapWithErrors(
+		nil,
+		"failed to load user",
+		erax.New("db timeout"),
+		erax.New("cache miss"),
+	)
+
+	return erax.WithMeta(err, "service error", erax.F("code", "500"))
+}
+
+// printNode renders a node and its children with a custom layout.
+func printNode(n erax.Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	kind := "erax"
+	if n.IsForeign() {
+		kind = "foreign"
+	}
+	fmt.Printf("%s%s (%s)\n", indent, n.Message(), kind)
+
+	for _, field := range n.Meta() {
+		fmt.Printf("%s  %s = %s\n", indent, field.Key, field.Value)
+	}
+
+	for _, child := range n.Errors() {
+		printNode(child, depth+1)
+	}
+
+	if cause := n.Cause(); cause != nil {
+		printNode(cause, depth+1)
+	}
+}
+
+func inspectShowcase() {
+	// Inspect gives a read-only view of the error tree,
+	// so custom renderers don't have to go through JSON maps.
+	printNode(erax.Inspect(newError()), 0)
+}
+
+func walkShowcase() {
+	// Walk visits every node without recursion, and is safe on cyclic trees.
+	//
+	// Return WalkSkip to skip the children of a node, or WalkStop to stop the walk.
+	erax.Walk(newError(), func(n erax.Node, path []int, depth int) erax.WalkAction {
+		fmt.Println(depth, path, n.Message())
+		return erax.WalkContinue
+	})
+
+	fmt.Println()
+
+	// WalkBreadthFirst visits the nodes level by level.
+	erax.WalkBreadthFirst(newError(), func(n erax.Node, path []int, depth int) erax.WalkAction {
+		if depth > 2 {
+			return erax.WalkStop
+		}
+		fmt.Println(depth, path, n.Message())
+		return erax.WalkContinue
+	})
+
+	fmt.Println()
+
+	// Nil errors passed to WrapWithErrors are skipped, so fn always gets a node.
+	err := erax.WrapWithErrors(nil, "failed to load user", nil, erax.New("cache miss"))
+	erax.Walk(err, func(n erax.Node, path []int, depth int) erax.WalkAction {
+		fmt.Println(depth, path, n.Message())
+		return erax.WalkContinue
+	})
+}
+
+func queryShowcase() {
+	err := erax.WrapWithErrors(
+		nil,
+		"failed to load user",
+		erax.WithMeta(erax.New("db timeout"), "db", erax.F("code", "504")),
+		erax.WithMeta(erax.New("cache miss"), "cache", erax.F("code", "404")),
+	)
+
+	// Query selects nodes and values with a small JSONPath-like language.
+	//
+	// It works the same way on maps produced by FormatToJSONMap.
+	codes, _ := erax.Query(err, "$..errs[*].meta.code")
+	fmt.Println("codes:", codes)
+
+	failed, _ := erax.Query(err, `$..[?(@.meta.code ^= "5")].message`)
+	fmt.Println("server errors:", failed)
+
+	// CompileQuery parses a query once, for rules evaluated over and over.
+	q, qerr := erax.CompileQuery(`..[?(@.message == "cache")]`)
+	if qerr != nil {
+		fmt.Println("bad query:", qerr)
+		return
+	}
+	fmt.Println("cache errors:", len(q.Eval(erax.FormatToJSONMap(err))))
+}
+
+// ValidationError is an error type of the application.
+type ValidationError struct {
+	Field string
+}
+
+func (e *ValidationError) Error() string { return "invalid " + e.Field }
+
+func asAllShowcase() {
+	err := erax.WrapWithErrors(
+		nil,
+		"validation failed",
+		&ValidationError{Field: "name"},
+		erax.Wrap(&ValidationError{Field: "email"}, "contact"),
+	)
+
+	// errors.As stops at the first match, AsAll finds every one of them.
+	for _, v := range erax.AsAll[*ValidationError](err) {
+		fmt.Println("invalid field:", v.Field)
+	}
+
+	// As returns the first match only.
+	if v, ok := erax.As[*ValidationError](err); ok {
+		fmt.Println("first invalid field:", v.Field)
+	}
+
+	// FindAll returns the matching nodes with their paths.
+	for _, m := range erax.FindAll(err, func(n erax.Node) bool { return n.IsForeign() }) {
+		fmt.Println(m.Path, m.Node.Message())
+	}
+}
+
+func diffShowcase() {
+	expected := newError()
+	actual := erax.WithMeta(
+		erax.WrapWithErrors(nil, "failed to load user", erax.New("db unavailable"), erax.New("cache miss")),
+		"service error",
+		erax.F("code", "503"),
+	)
+
+	// Diff compares two error trees node by node,
+	// so a failing test can say what changed instead of printing two whole traces.
+	diff := erax.Diff(expected, actual)
+	if diff.Equal() {
+		return
+	}
+
+	for _, c := range diff.Changes {
+		fmt.Println(c)
+	}
+
+	// String renders both trees merged into one, marking what was removed, added or changed.
+	fmt.Print(diff)
+}
+
+func main() {
+	fmt.Println()
+
+	inspectShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	walkShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	queryShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	asAllShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	diffShowcase()
+
+	fmt.Println()
+}
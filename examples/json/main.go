@@ -28,6 +28,26 @@ func formatToJSONStringShowcase() {
 	fmt.Println(json)
 }
 
+func formatJSONPrettyShowcase() {
+	err := erax.New("db timeout")
+	err = erax.Wrap(err, "failed to load user")
+
+	err = erax.WithMeta(
+		err,
+		"service error",
+		erax.F("code", "500"),
+	)
+
+	// FormatJSONPretty writes the same JSON, indented for humans.
+	fmt.Println(erax.FormatJSONPretty(err, "  "))
+
+	// SetJSONColor colors keys and values with the current theme.
+	// Colored output is meant for terminals: it's not valid JSON anymore.
+	erax.SetJSONColor(true)
+	fmt.Println(erax.FormatJSONPretty(err, "  "))
+	erax.SetJSONColor(false)
+}
+
 func formatToJSONMapShowcase() {
 	err := erax.New("duplicate email")
 
@@ -73,6 +93,12 @@ func main() {
 	fmt.Println("=============================")
 	fmt.Println()
 
+	formatJSONPrettyShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
 	formatToJSONMapShowcase()
 
 	fmt.Println()
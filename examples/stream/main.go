@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/DangeL187/erax"
+)
+
+func writeTraceShowcase() {
+	err := erax.New("db timeout")
+	err = erax.Wrap(err, "failed to load user")
+	err = erax.WithMeta(err, "service error", erax.F("code", "500"))
+
+	// WriteTrace streams the trace straight into any io.Writer.
+	//
+	// Unlike Format, it never builds the whole trace as a string,
+	// so even huge error trees can be written with bounded memory.
+	n, writeErr := erax.WriteTrace(os.Stdout, err)
+	if writeErr != nil {
+		fmt.Println("write failed:", writeErr)
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("bytes written:", n)
+}
+
+func writerToShowcase() {
+	err := erax.WrapWithErrors(
+		nil,
+		"validation failed",
+		erax.New("email is invalid"),
+		erax.New("password is too short"),
+	)
+
+	// erax errors also implement io.WriterTo,
+	// so they can be passed wherever a WriterTo is accepted.
+	if wt, ok := err.(io.WriterTo); ok {
+		_, _ = wt.WriteTo(os.Stdout)
+	}
+}
+
+func main() {
+	fmt.Println()
+
+	writeTraceShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	writerToShowcase()
+
+	fmt.Println()
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/DangeL187/erax"
 )
@@ -14,6 +15,30 @@ func main() {
 	erax.SetKeyColor("#209fb5")
 	erax.SetValueColor("#dd7878")
 
+	// Long messages and metadata values can be wrapped to fit the terminal.
+	//
+	// WidthAuto only wraps traces written straight to a terminal with WriteTrace.
+	// Pass an explicit number of columns instead of WidthAuto to pin the width.
+	erax.SetWidth(erax.WidthAuto)
+
+	// Metadata values can be aligned by the longest key of each error.
+	erax.SetAlignMeta(true)
+
+	// Huge error trees can be cut down to size.
+	//
+	// Skipped parts collapse into markers like "… 1,234 more errors".
+	// Depth and children limits also apply to the JSON encoders.
+	erax.SetMaxDepth(16)
+	erax.SetMaxChildren(32)
+	erax.SetMaxLines(256)
+
+	// Source locations and error codes can become clickable terminal hyperlinks.
+	//
+	// "file" and "line" metadata open the editor, "code" metadata opens its docs page.
+	// Like colors, they are left out when the terminal has no color support or NO_COLOR is set.
+	erax.SetEditorURL("vscode://file/{path}:{line}")
+	erax.SetDocsURL("https://example.com/errors/{code}")
+
 	err := erax.New("db timeout")
 	err = erax.Wrap(err, "failed to load user")
 	err = erax.WithMeta(
@@ -21,7 +46,9 @@ func main() {
 		"service error",
 		erax.F("code", "500"),
 		erax.F("env", "production"),
+		erax.F("file", "/app/user/service.go:42"),
 	)
 
-	fmt.Println(erax.Format(err))
+	_, _ = erax.WriteTrace(os.Stdout, err)
+	fmt.Println()
 }
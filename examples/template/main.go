@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/DangeL187/erax"
+)
+
+func newError() error {
+	err := erax.WrapWithErrors(
+		nil,
+		"failed to load user",
+		erax.New("db timeout"),
+		erax.New("cache miss"),
+	)
+
+	return erax.WithMeta(err, "service error", erax.F("code", "500"))
+}
+
+func walkShowcase() {
+	// walk flattens the tree, so the whole layout fits in a single range.
+	//
+	// Every node knows its Depth, Path, Meta and whether it IsLast or IsForeign.
+	f, err := erax.NewTemplateFormatter(
+		`{{range walk .}}{{indent .Depth}}{{if .IsForeign}}{{styleForeign .Message}}{{else}}{{styleError .Message}}{{end}}` +
+			`{{range .Meta}} [{{styleKey .Key}}={{styleValue .Value}}]{{end}}` + "\n{{end}}",
+	)
+	if err != nil {
+		fmt.Println("bad template:", err)
+		return
+	}
+
+	out, err := f.Format(newError())
+	if err != nil {
+		fmt.Println("template failed:", err)
+		return
+	}
+
+	fmt.Print(out)
+}
+
+func recursiveShowcase() {
+	// Templates can also recurse into Children on their own.
+	f, err := erax.NewTemplateFormatter(
+		`{{define "node"}}<{{.Message}}{{range .Children}} {{template "node" .}}{{end}}>{{end}}{{template "node" .}}`,
+	)
+	if err != nil {
+		fmt.Println("bad template:", err)
+		return
+	}
+
+	out, err := f.Format(newError())
+	if err != nil {
+		fmt.Println("template failed:", err)
+		return
+	}
+
+	fmt.Println(out)
+}
+
+func main() {
+	fmt.Println()
+
+	walkShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	recursiveShowcase()
+
+	fmt.Println()
+}
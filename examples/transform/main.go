@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DangeL187/erax"
+)
+
+func newError() error {
+	err := erax.WrapWithErrors(
+		nil,
+		"failed to load users",
+		erax.Wrap(erax.New("db timeout"), "internal: retry"),
+		erax.New("cache miss"),
+		erax.New("cache miss"),
+	)
+
+	return erax.WithMeta(err, "service error", erax.F("code", "500"), erax.F("token", "secret"))
+}
+
+func mapShowcase() {
+	// Map rewrites the message and metadata of every erax error.
+	//
+	// Here it redacts secrets before logging.
+	err := erax.Map(newError(), func(n erax.Node) (string, []erax.MetaField) {
+		meta := make([]erax.MetaField, 0, len(n.Meta()))
+		for _, field := range n.Meta() {
+			if field.Key == "token" {
+				field.Value = "***"
+			}
+			meta = append(meta, field)
+		}
+		return n.Message(), meta
+	})
+
+	fmt.Println(erax.Format(err))
+}
+
+func filterShowcase() {
+	// Filter drops noisy intermediate wraps. Their children take their place.
+	err := erax.Filter(newError(), func(n erax.Node) bool {
+		return !strings.HasPrefix(n.Message(), "internal:")
+	})
+
+	fmt.Println(erax.Format(err))
+}
+
+func pruneShowcase() {
+	// Prune cuts everything below the given depth.
+	fmt.Println(erax.Format(erax.Prune(newError(), 2)))
+}
+
+func collapseDuplicatesShowcase() {
+	// CollapseDuplicates merges identical siblings and counts them.
+	fmt.Println(erax.Format(erax.CollapseDuplicates(newError())))
+}
+
+func main() {
+	fmt.Println()
+
+	mapShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	filterShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	pruneShowcase()
+
+	fmt.Println()
+	fmt.Println("=============================")
+	fmt.Println()
+
+	collapseDuplicatesShowcase()
+
+	fmt.Println()
+}
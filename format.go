@@ -2,7 +2,7 @@ package erax
 
 import (
 	"fmt"
-	"strings"
+	"io"
 )
 
 // Format pretty-prints the error trace.
@@ -14,112 +14,177 @@ func Format(err error) string {
 	return fmt.Sprintf("%+v", err)
 }
 
-// formatErrorChain recursively formats an error chain into a string builder with tree visualization.
-func formatErrorChain(sb *strings.Builder, err *errorType, isParentNested bool, levels []bool) {
+// WriteTrace streams the error trace to w.
+//
+// Unlike Format, it never materializes the whole trace as a string,
+// so even very large trees can go straight to stderr or a file with bounded memory.
+//
+// Returns the number of bytes written and the first write error encountered.
+func WriteTrace(w io.Writer, err error) (int64, error) {
+	if err == nil {
+		return 0, nil
+	}
+
+	tw := newTraceWriter(w)
+	writeTrace(tw, err, rootNodeID())
+
+	return tw.close()
+}
+
+// writeTrace renders the error trace into the trace writer. id is the path ID of the root error, or empty.
+func writeTrace(tw *traceWriter, err error, id string) {
+	if e, isErax := asErax(err); isErax {
+		tw.WriteString(message)
+		tw.WriteByte('\n')
+		tw.refs = newNodeRefs(e)
+		formatErrorChain(tw, e, false, nil, 1, id)
+	} else {
+		_, _ = fmt.Fprintf(tw, "%+v", err)
+	}
+
+	tw.writeHiddenLines()
+}
+
+// formatErrorChain recursively formats an error chain into the trace writer with tree visualization.
+//
+// The depth of the root error is 1. Subtrees below the depth limit and children over the limit
+// are replaced with elision markers. Nodes reachable more than once are rendered only once,
+// and later occurrences are rendered as back-references to their label.
+// If node IDs are enabled, id is the path ID of the error, otherwise it's empty.
+func formatErrorChain(tw *traceWriter, err *errorType, isParentNested bool, levels []bool, depth int, id string) {
 	hasCause := err.cause != nil
 	hasErrs := len(err.errs) > 0
 	isNested := !hasCause && hasErrs
 
+	// isEnd is set for a node without children, so nothing is drawn below it.
+	isEnd := !hasCause && !hasErrs
+
 	if levels == nil {
-		if isNested {
-			sb.WriteString(branchEndBig)
+		// The chain ends with a node that has errors, or with a node without children at all.
+		if isNested || isEnd {
+			tw.WriteString(branchEndBig)
 		} else {
-			sb.WriteString(branchNextBig)
+			tw.WriteString(branchNextBig)
 		}
-		levels = append(levels, isNested)
+		levels = append(levels, isNested || isEnd)
 	}
 
-	writeFormattedError(sb, err.msg, isParentNested, hasCause, false, levels)
-	sb.WriteByte('\n')
+	ref := tw.refs.enterAt(err, id)
+	defer tw.refs.leave(ref)
+
+	if label := nodeLabel(ref, id); label != "" {
+		writeElision(tw, label)
+		tw.WriteByte(' ')
+	}
+
+	writeFormattedError(tw, err.msg, isParentNested, hasCause, false, levels)
+	writeMeta(tw, err.meta, isParentNested, isEnd, levels)
+
+	// A node without children ends without a line break, the same way a leaf written by writeLeaf does.
+	if isEnd {
+		return
+	}
 
-	writeMeta(sb, err.meta, isParentNested, levels)
+	tw.WriteByte('\n')
 
-	if !hasCause && !hasErrs {
+	if tw.limits.depth > 0 && depth >= tw.limits.depth {
+		if isNested {
+			writeIndent(tw, levels)
+			tw.WriteString(branchEndBig)
+		} else {
+			writeLeafCauseBranch(tw, isParentNested, levels)
+		}
+		writeElision(tw, deeperLevelsMarker(treeHeight(err)-1))
 		return
 	}
 
-	for i, ue := range err.errs {
+	shown, hidden := tw.limits.splitChildren(len(err.errs))
+
+	for i, ue := range err.errs[:shown] {
 		if i > 0 {
-			sb.WriteByte('\n')
+			tw.WriteByte('\n')
 		}
 
-		isLast := i == len(err.errs)-1
+		isLast := i == shown-1 && hidden == 0
+		childID := childNodeID(id, i)
 
 		next, isErax := asErax(ue)
-		if isErax {
-			writeIndent(sb, levels)
+		ref := tw.refs.seen(next)
+		if isErax && ref == nil {
+			writeIndent(tw, levels)
 			if isNested {
 				if isLast {
-					sb.WriteString(branchS)
-					sb.WriteByte('\n')
-					writeIndent(sb, levels)
-					sb.WriteString("  ")
+					tw.WriteString(branchS)
+					tw.WriteByte('\n')
+					writeIndent(tw, levels)
+					tw.WriteString("  ")
 				} else {
-					sb.WriteString(branchH)
-					sb.WriteByte('\n')
-					writeIndent(sb, levels)
-					sb.WriteString(branchMid)
+					tw.WriteString(branchH)
+					tw.WriteByte('\n')
+					writeIndent(tw, levels)
+					tw.WriteString(branchMid)
 				}
 				if next.cause == nil {
-					sb.WriteString(branchEnd)
+					tw.WriteString(branchEnd)
 				} else {
-					sb.WriteString(branchNext)
+					tw.WriteString(branchNext)
 				}
 			}
 
-			formatErrorChain(sb, next, isNested, append(levels, isLast))
+			formatErrorChain(tw, next, isNested, append(levels, isLast), depth+1, childID)
 		} else {
-			writeIndent(sb, levels)
+			writeIndent(tw, levels)
 
 			if isLast {
-				sb.WriteString(branchEndBig)
+				tw.WriteString(branchEndBig)
 			} else {
-				sb.WriteString(branchNextBig)
+				tw.WriteString(branchNextBig)
 			}
 
-			writeFormattedError(sb, fmt.Sprintf("%+v", ue), isNested, false, false, append(levels, isLast))
+			writeLeaf(tw, ue, ref, isNested, append(levels, isLast), childID)
 		}
 	}
 
+	if hidden > 0 {
+		tw.WriteByte('\n')
+		writeIndent(tw, levels)
+		tw.WriteString(branchEndBig)
+		writeElision(tw, moreErrorsMarker(hidden))
+	}
+
 	if hasCause {
-		var childLevels []bool
-		if len(levels) > 1 {
-			childLevels = levels[:len(levels)-1]
-		}
+		next, isErax := asErax(err.cause)
+		ref := tw.refs.seen(next)
+		if isErax && ref == nil {
+			var childLevels []bool
+			if len(levels) > 1 {
+				childLevels = levels[:len(levels)-1]
+			}
 
-		writeIndent(sb, childLevels)
+			writeIndent(tw, childLevels)
 
-		next, isErax := asErax(err.cause)
-		if isErax {
 			if len(levels) > 0 && levels[len(levels)-1] {
-				sb.WriteString("  ")
-				sb.WriteString(branchNext)
+				tw.WriteString("  ")
+				if next.cause == nil {
+					tw.WriteString(branchEnd)
+				} else {
+					tw.WriteString(branchNext)
+				}
 				childLevels = append(childLevels, true)
 			} else if isParentNested {
-				sb.WriteString(branchMid)
+				tw.WriteString(branchMid)
 				if next.cause == nil {
-					sb.WriteString(branchEnd)
+					tw.WriteString(branchEnd)
 				} else {
-					sb.WriteString(branchNext)
+					tw.WriteString(branchNext)
 				}
 				childLevels = append(childLevels, false)
 			}
 
-			formatErrorChain(sb, next, isParentNested, childLevels)
+			formatErrorChain(tw, next, isParentNested, childLevels, depth+1, causeNodeID(id))
 		} else {
-			if len(levels) > 0 && levels[len(levels)-1] {
-				sb.WriteString("  ")
-				sb.WriteString(branchEnd)
-				childLevels = levels
-			} else if isParentNested {
-				sb.WriteString(branchMid)
-				sb.WriteString(branchEnd)
-				childLevels = levels
-			} else {
-				sb.WriteString(branchEndBig)
-				childLevels = append(childLevels, true)
-			}
-			writeFormattedError(sb, fmt.Sprintf("%+v", err.cause), isParentNested, false, false, childLevels)
+			childLevels := writeLeafCauseBranch(tw, isParentNested, levels)
+			writeLeaf(tw, err.cause, ref, isParentNested, childLevels, causeNodeID(id))
 		}
 	}
 }
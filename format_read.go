@@ -0,0 +1,271 @@
+package erax
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Columns of the parts of a node relative to the column of its message, as written by formatErrorChain.
+const (
+	traceMetaOffset  = 4
+	traceChildOffset = 5
+)
+
+// traceParser reconstructs an error tree from the uncolored output of formatErrorChain.
+//
+// Every node is identified by the column its message starts at: the causes of a node start at the same column,
+// its metadata right under it, and the errors of a WrapWithErrors node a level deeper.
+type traceParser struct {
+	root *parsedNode
+
+	// path holds the nodes from the root to the current one.
+	path  []traceEntry
+	nodes []*parsedNode
+	ids   map[string]*errorType
+
+	// group is set after a "╮" line, which starts a new error of a WrapWithErrors node.
+	group bool
+
+	// inMeta is set while the metadata of the current node is being read.
+	inMeta     bool
+	valueCol   int
+	valueLines int
+}
+
+// traceEntry is a parsed node with the column its message starts at.
+type traceEntry struct {
+	n   *parsedNode
+	col int
+}
+
+func (p *traceParser) parse(text string) (error, error) {
+	lines := strings.Split(strings.TrimRight(text, "\r\n"), "\n")
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if i == 0 && strings.TrimSpace(line) == strings.TrimSpace(traceHeader) {
+			continue
+		}
+
+		if err := p.parseLine([]rune(line)); err != nil {
+			return nil, fmt.Errorf("erax: invalid trace at line %d: %w", i+1, err)
+		}
+	}
+
+	if p.root == nil {
+		return errors.New(strings.TrimSpace(text)), nil
+	}
+
+	p.resolveBackRefs()
+
+	return p.root.build(), nil
+}
+
+func (p *traceParser) parseLine(line []rune) error {
+	col, dashes := traceGlyph(line)
+
+	if col == -1 {
+		if isTraceGroup(line) {
+			p.group = true
+			return nil
+		}
+		if isHiddenLinesMarker(line) {
+			return nil
+		}
+
+		p.continueLine(line)
+		return nil
+	}
+
+	text := string(line[col:])
+
+	if p.root == nil {
+		p.root = p.newNode(text)
+		p.path = append(p.path, traceEntry{n: p.root, col: col})
+		return nil
+	}
+
+	current := p.path[len(p.path)-1]
+
+	if !p.group && dashes == 1 && col == current.col+traceMetaOffset {
+		p.addMeta(current.n, text, col)
+		return nil
+	}
+
+	if p.group || dashes > 1 {
+		for i := len(p.path) - 1; i >= 0; i-- {
+			if p.path[i].col+traceChildOffset != col {
+				continue
+			}
+
+			parent := p.path[i].n
+			child := p.newNode(text)
+			parent.errs = append(parent.errs, child)
+			p.path = append(p.path[:i+1], traceEntry{n: child, col: col})
+			p.group = false
+			return nil
+		}
+	}
+
+	for i := len(p.path) - 1; i >= 0; i-- {
+		if p.path[i].col != col {
+			continue
+		}
+
+		cause := p.newNode(text)
+		p.path[i].n.cause = cause
+		p.path = append(p.path[:i], traceEntry{n: cause, col: col})
+		p.group = false
+		return nil
+	}
+
+	return fmt.Errorf("unexpected indentation at column %d", col)
+}
+
+// newNode starts a new node with the first line of its message, registering its label if it has one.
+func (p *traceParser) newNode(text string) *parsedNode {
+	n := &parsedNode{e: &errorType{}}
+
+	if label, length := parseLabel(text); label != "" {
+		text = text[length:]
+		n.labeled = true
+
+		if p.ids == nil {
+			p.ids = make(map[string]*errorType)
+		}
+		p.ids[label] = n.e
+	}
+
+	n.e.msg = text
+	p.nodes = append(p.nodes, n)
+	p.inMeta = false
+
+	return n
+}
+
+// addMeta adds a metadata field written as "key: value" to the node.
+func (p *traceParser) addMeta(n *parsedNode, text string, col int) {
+	key, value := text, ""
+	if idx := strings.Index(text, ": "); idx != -1 {
+		key, value = text[:idx], text[idx+2:]
+	} else {
+		key = strings.TrimSuffix(key, ":")
+	}
+
+	// Aligned values are padded after the colon, and their following lines are shifted under the first one.
+	// Values written below the key start one column further than the key.
+	aligned := strings.TrimLeft(value, " ")
+	p.valueCol = col + len([]rune(text)) - len([]rune(aligned))
+	p.valueLines = 0
+	if aligned != "" {
+		p.valueLines = 1
+	} else {
+		p.valueCol = col + 1
+	}
+
+	n.e.meta = append(n.e.meta, MetaField{Key: key, Value: aligned})
+	p.inMeta = true
+}
+
+// continueLine adds a line without a branch to the current metadata value or message.
+func (p *traceParser) continueLine(line []rune) {
+	if len(p.path) == 0 {
+		return
+	}
+
+	current := p.path[len(p.path)-1]
+
+	if p.inMeta {
+		field := &current.n.e.meta[len(current.n.e.meta)-1]
+		if p.valueLines > 0 {
+			field.Value += "\n"
+		}
+		field.Value += sliceColumns(line, p.valueCol)
+		p.valueLines++
+		return
+	}
+
+	current.n.e.msg += "\n" + sliceColumns(line, current.col)
+}
+
+// resolveBackRefs turns nodes ending with a back-reference like "(see #1)" into references to the labeled node.
+func (p *traceParser) resolveBackRefs() {
+	for _, n := range p.nodes {
+		idx := strings.LastIndex(n.e.msg, " (")
+		if idx == -1 {
+			continue
+		}
+
+		suffix := n.e.msg[idx+1:]
+		label, length := parseBackRef(suffix)
+		if label == "" || length != len(suffix) {
+			continue
+		}
+
+		if ref, ok := p.ids[label]; ok {
+			n.ref = ref
+		}
+	}
+}
+
+// traceGlyph finds the branch leading to the text of a line, like "├── " or "╰─ ".
+//
+// Returns the column the text starts at and the number of dashes of the branch, or -1 if there is no branch.
+func traceGlyph(line []rune) (int, int) {
+	dashes := 0
+	for i, r := range line {
+		if !isTraceRune(r) {
+			break
+		}
+
+		if r != '─' {
+			dashes = 0
+			continue
+		}
+
+		dashes++
+		if i+1 < len(line) && line[i+1] == ' ' {
+			return i + 2, dashes
+		}
+	}
+
+	return -1, 0
+}
+
+// isTraceGroup reports whether a line is the "├╮" or "╰╮" opening an error of a WrapWithErrors node.
+func isTraceGroup(line []rune) bool {
+	hasCorner := false
+	for _, r := range line {
+		if !isTraceRune(r) {
+			return false
+		}
+		if r == '╮' {
+			hasCorner = true
+		}
+	}
+
+	return hasCorner
+}
+
+// isHiddenLinesMarker reports whether a line is the marker written in place of the lines past the line limit.
+func isHiddenLinesMarker(line []rune) bool {
+	s := strings.TrimSpace(string(line))
+	return strings.HasPrefix(s, "… ") && (strings.HasSuffix(s, " more lines") || strings.HasSuffix(s, " more line"))
+}
+
+func isTraceRune(r rune) bool {
+	switch r {
+	case ' ', '│', '├', '╰', '─', '╮':
+		return true
+	}
+	return false
+}
+
+// sliceColumns returns the part of a line starting at the given column.
+func sliceColumns(line []rune, col int) string {
+	if col >= len(line) {
+		return ""
+	}
+	return string(line[col:])
+}
@@ -1,14 +1,119 @@
 package erax
 
-import "strings"
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// traceBufferSize is the size of the buffer the trace is rendered into before it is flushed to the destination.
+const traceBufferSize = 4096
+
+var traceWriterPool = sync.Pool{
+	New: func() any {
+		return &traceWriter{Writer: bufio.NewWriterSize(nil, traceBufferSize)}
+	},
+}
+
+// traceWriter is the sink the error trace is rendered into.
+//
+// It buffers output in a fixed-size buffer and counts the bytes flushed to the destination,
+// so traces of any size can be streamed without being materialized as a whole.
+//
+// When the trace is wrapped, it also keeps track of the display column of the current row.
+// When the number of lines is limited, it drops everything past the limit and counts the dropped lines.
+type traceWriter struct {
+	*bufio.Writer
+	dst   countingWriter
+	width int
+	col   int
+	links bool
+	align bool
+
+	// limits are the limits set when the trace was started, so a trace is rendered with the same ones throughout.
+	limits      limits
+	lines       int
+	hiddenLines int
+	lastHidden  byte
+
+	refs nodeRefs
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// newTraceWriter returns a pooled trace writer that writes to w. It must be released with close.
+func newTraceWriter(w io.Writer) *traceWriter {
+	tw := traceWriterPool.Get().(*traceWriter)
+	tw.dst = countingWriter{w: w}
+	tw.Reset(&tw.dst)
+	tw.width = resolveWidth(w)
+	tw.links = hyperlinksEnabled()
+	tw.align = alignMeta
+	tw.limits = currentLimits()
+	tw.col = 0
+	tw.lines = 0
+	tw.hiddenLines = 0
+	tw.lastHidden = 0
+	return tw
+}
+
+func (tw *traceWriter) WriteString(s string) (int, error) {
+	if tw.limits.lines > 0 {
+		s = tw.limitLines(s)
+	}
+	if tw.width > 0 {
+		tw.advance(s)
+	}
+	return tw.Writer.WriteString(s)
+}
+
+func (tw *traceWriter) WriteByte(c byte) error {
+	if tw.limits.lines > 0 && tw.limitLines(string(c)) == "" {
+		return nil
+	}
+	if tw.width > 0 {
+		if c == '\n' {
+			tw.col = 0
+		} else {
+			tw.col++
+		}
+	}
+	return tw.Writer.WriteByte(c)
+}
+
+// close flushes the buffered output and returns the writer to the pool.
+//
+// Returns the number of bytes written to the destination and the first write error encountered.
+func (tw *traceWriter) close() (int64, error) {
+	err := tw.Flush()
+	n := tw.dst.n
+
+	tw.dst = countingWriter{}
+	tw.refs = nodeRefs{}
+	tw.Reset(nil)
+	traceWriterPool.Put(tw)
+
+	return n, err
+}
 
 // writeFormattedError formats and writes an error message, handling multi-line messages with proper indentation
-func writeFormattedError(sb *strings.Builder, text string, isParentNested, hasCause, isAlien bool, levels []bool) {
-	if indexByte(text, '\n') == -1 {
+func writeFormattedError(tw *traceWriter, text string, isParentNested, hasCause, isAlien bool, levels []bool) {
+	if indexByte(text, '\n') == -1 && !tw.overflows(text) {
 		if isAlien {
-			sb.WriteString(alienText.Render(text))
+			tw.WriteString(alienText.Render(text))
 		} else {
-			sb.WriteString(errorText.Render(text))
+			tw.WriteString(errorText.Render(text))
 		}
 		return
 	}
@@ -24,11 +129,11 @@ func writeFormattedError(sb *strings.Builder, text string, isParentNested, hasCa
 
 	isLast := len(levels) > 0 && levels[len(levels)-1]
 
-	for start < textLen {
-		idx := indexByte(text[start:], '\n')
-
-		var line string
-		if idx == -1 {
+	var line, rest string
+	for start < textLen || rest != "" {
+		if rest != "" {
+			line = rest
+		} else if idx := indexByte(text[start:], '\n'); idx == -1 {
 			line = text[start:]
 			start = textLen
 		} else {
@@ -36,46 +141,105 @@ func writeFormattedError(sb *strings.Builder, text string, isParentNested, hasCa
 			start += idx + 1
 		}
 
+		line, rest = tw.fit(line)
+
 		if isAlien {
-			sb.WriteString(alienText.Render(line))
+			tw.WriteString(alienText.Render(line))
 		} else {
-			sb.WriteString(errorText.Render(line))
+			tw.WriteString(errorText.Render(line))
 		}
-		if start < textLen {
-			sb.WriteByte('\n')
+		if start < textLen || rest != "" {
+			tw.WriteByte('\n')
 
-			writeIndent(sb, childLevels)
+			writeIndent(tw, childLevels)
 			if hasCause && isParentNested {
 				if isLast {
-					sb.WriteByte(' ')
-					sb.WriteString(branchMid)
-					sb.WriteByte(' ')
+					tw.WriteByte(' ')
+					tw.WriteString(branchMid)
+					tw.WriteByte(' ')
 				} else {
-					sb.WriteString(branchTwix)
+					tw.WriteString(branchTwix)
 				}
 			} else if !hasCause && isLast {
-				sb.WriteString("    ")
+				tw.WriteString("    ")
 			} else if hasCause != isParentNested {
-				sb.WriteString(branchMid)
-				sb.WriteString("  ")
+				tw.WriteString(branchMid)
+				tw.WriteString("  ")
 			}
-			sb.WriteByte(' ')
+			tw.WriteByte(' ')
 		}
 		lineIdx++
 	}
 }
 
-func writeIndent(sb *strings.Builder, levels []bool) {
+// writeLeafCauseBranch writes the branch leading to a cause rendered as a single leaf.
+//
+// Returns the levels the leaf text is written at.
+func writeLeafCauseBranch(tw *traceWriter, isParentNested bool, levels []bool) []bool {
+	var childLevels []bool
+	if len(levels) > 1 {
+		childLevels = levels[:len(levels)-1]
+	}
+
+	writeIndent(tw, childLevels)
+
+	if len(levels) > 0 && levels[len(levels)-1] {
+		tw.WriteString("  ")
+		tw.WriteString(branchEnd)
+		return levels
+	}
+
+	if isParentNested {
+		tw.WriteString(branchMid)
+		tw.WriteString(branchEnd)
+		return levels
+	}
+
+	tw.WriteString(branchEndBig)
+	return append(childLevels, true)
+}
+
+// writeLeaf writes an error rendered as a single leaf:
+// either a non-erax error, or an erax node that was already rendered, followed by a back-reference to it.
+func writeLeaf(tw *traceWriter, err error, ref *nodeRef, isParentNested bool, levels []bool, id string) {
+	if id != "" {
+		writeElision(tw, "#"+id)
+		tw.WriteByte(' ')
+	}
+
+	if ref == nil {
+		writeFormattedError(tw, fmt.Sprintf("%+v", err), isParentNested, false, false, levels)
+		return
+	}
+
+	writeFormattedError(tw, err.Error(), isParentNested, false, false, levels)
+	tw.WriteByte(' ')
+	writeElision(tw, ref.backRef())
+}
+
+// writeElision writes a marker standing in for the part of the trace that was left out.
+func writeElision(tw *traceWriter, text string) {
+	tw.WriteString(elisionText.Render(text))
+}
+
+func writeIndent(tw *traceWriter, levels []bool) {
 	for _, isLast := range levels {
 		if isLast {
-			sb.WriteString("     ")
+			tw.WriteString("     ")
 		} else {
-			sb.WriteString(branchMid)
-			sb.WriteString("   ")
+			tw.WriteString(branchMid)
+			tw.WriteString("   ")
 		}
 	}
 }
 
+// writeSpaces writes n spaces.
+func writeSpaces(tw *traceWriter, n int) {
+	for i := 0; i < n; i++ {
+		tw.WriteByte(' ')
+	}
+}
+
 // indexByte returns the index of the first occurrence of a byte in a string.
 func indexByte(s string, c byte) int {
 	for i := 0; i < len(s); i++ {
@@ -0,0 +1,49 @@
+package erax
+
+import "bytes"
+
+// ToDOT renders the error tree as a Graphviz DOT digraph, for diagrams in design reviews and wiki pages.
+//
+// Every error becomes a node labeled with its message and metadata, and points to its cause and its errors.
+// Cause edges are solid and labeled "cause", and edges to the errors of WrapWithErrors nodes are dashed
+// and labeled with their index. Non-erax errors are drawn as dashed ellipses.
+// Errors reachable more than once become a single node with several incoming edges.
+func ToDOT(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	writeDOT(buf, newErrorGraph(err))
+	res := buf.String()
+
+	if buf.Cap() <= 16384 {
+		bufferPool.Put(buf)
+	}
+
+	return res
+}
+
+// ToMermaid renders the error tree as a Mermaid flowchart, for diagrams in Markdown pages.
+//
+// The graph is the same as the one of ToDOT: cause edges are solid, edges to the errors of WrapWithErrors nodes
+// are dotted, and non-erax errors are drawn as dashed stadiums.
+func ToMermaid(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	writeMermaid(buf, newErrorGraph(err))
+	res := buf.String()
+
+	if buf.Cap() <= 16384 {
+		bufferPool.Put(buf)
+	}
+
+	return res
+}
@@ -0,0 +1,231 @@
+package erax
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// errorGraph is an error tree flattened to nodes and edges.
+type errorGraph struct {
+	nodes []graphNode
+	edges []graphEdge
+}
+
+// graphNode is a single error of an errorGraph.
+type graphNode struct {
+	label   string
+	meta    []MetaField
+	foreign bool
+}
+
+// graphEdge points from an error to its cause, or to one of its errors if index is not -1.
+type graphEdge struct {
+	from, to int
+	index    int
+}
+
+// newErrorGraph flattens the error tree to a graph, in the order the errors are rendered by Format.
+//
+// Errors reachable more than once are added once, so shared errors and cycles are preserved.
+func newErrorGraph(root error) errorGraph {
+	var g errorGraph
+	ids := make(map[error]int)
+
+	// node returns the index of an error in the graph, adding the error if it isn't there yet.
+	node := func(err error) (int, bool) {
+		trackable := isTrackable(err)
+		if trackable {
+			if id, ok := ids[err]; ok {
+				return id, false
+			}
+		}
+
+		id := len(g.nodes)
+		if trackable {
+			ids[err] = id
+		}
+
+		if e, isErax := asErax(err); isErax {
+			g.nodes = append(g.nodes, graphNode{label: e.msg, meta: e.meta})
+		} else {
+			g.nodes = append(g.nodes, graphNode{label: err.Error(), foreign: true})
+		}
+
+		return id, true
+	}
+
+	type frame struct {
+		err error
+		id  int
+	}
+
+	id, _ := node(root)
+	stack := []frame{{root, id}}
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		e, isErax := asErax(current.err)
+		if !isErax {
+			continue
+		}
+
+		// Children are pushed in reverse, so they are added in the order they are rendered: errors first, then the cause.
+		var children []frame
+		for i, ue := range e.errs {
+			id, added := node(ue)
+			g.edges = append(g.edges, graphEdge{from: current.id, to: id, index: i})
+			if added {
+				children = append(children, frame{ue, id})
+			}
+		}
+
+		if e.cause != nil {
+			id, added := node(e.cause)
+			g.edges = append(g.edges, graphEdge{from: current.id, to: id, index: -1})
+			if added {
+				children = append(children, frame{e.cause, id})
+			}
+		}
+
+		for i := len(children) - 1; i >= 0; i-- {
+			stack = append(stack, children[i])
+		}
+	}
+
+	return g
+}
+
+// writeDOT writes the graph in the Graphviz DOT language.
+func writeDOT(buf *bytes.Buffer, g errorGraph) {
+	buf.WriteString("digraph erax {\n")
+	buf.WriteString("\tnode [shape=box, style=rounded, fontname=monospace];\n")
+	buf.WriteString("\tedge [fontname=monospace];\n")
+
+	for id, n := range g.nodes {
+		buf.WriteString("\tn")
+		buf.WriteString(strconv.Itoa(id))
+		buf.WriteString(" [label=\"")
+		writeDOTEscaped(buf, n.label)
+		for _, field := range n.meta {
+			buf.WriteString(`\l`)
+			writeDOTEscaped(buf, field.Key+": "+field.Value)
+		}
+		if len(n.meta) > 0 {
+			buf.WriteString(`\l`)
+		}
+		buf.WriteByte('"')
+		if n.foreign {
+			buf.WriteString(", shape=ellipse, style=dashed")
+		}
+		buf.WriteString("];\n")
+	}
+
+	for _, edge := range g.edges {
+		buf.WriteString("\tn")
+		buf.WriteString(strconv.Itoa(edge.from))
+		buf.WriteString(" -> n")
+		buf.WriteString(strconv.Itoa(edge.to))
+		if edge.index == -1 {
+			buf.WriteString(" [label=\"cause\"];\n")
+			continue
+		}
+		buf.WriteString(" [label=\"errs[")
+		buf.WriteString(strconv.Itoa(edge.index))
+		buf.WriteString("]\", style=dashed];\n")
+	}
+
+	buf.WriteString("}\n")
+}
+
+// writeDOTEscaped writes text as part of a quoted DOT string, with line breaks as "\n".
+func writeDOTEscaped(buf *bytes.Buffer, text string) {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+// writeMermaid writes the graph as a Mermaid flowchart.
+func writeMermaid(buf *bytes.Buffer, g errorGraph) {
+	buf.WriteString("flowchart TD\n")
+
+	var foreign []string
+	for id, n := range g.nodes {
+		name := "n" + strconv.Itoa(id)
+
+		buf.WriteString("    ")
+		buf.WriteString(name)
+		if n.foreign {
+			buf.WriteString(`(["`)
+			foreign = append(foreign, name)
+		} else {
+			buf.WriteString(`["`)
+		}
+
+		writeMermaidEscaped(buf, n.label)
+		for _, field := range n.meta {
+			buf.WriteString("<br/>")
+			writeMermaidEscaped(buf, field.Key+": "+field.Value)
+		}
+
+		if n.foreign {
+			buf.WriteString(`"])`)
+		} else {
+			buf.WriteString(`"]`)
+		}
+		buf.WriteByte('\n')
+	}
+
+	for _, edge := range g.edges {
+		buf.WriteString("    n")
+		buf.WriteString(strconv.Itoa(edge.from))
+		if edge.index == -1 {
+			buf.WriteString(` -->|"cause"| n`)
+		} else {
+			buf.WriteString(` -.->|"errs[`)
+			buf.WriteString(strconv.Itoa(edge.index))
+			buf.WriteString(`]"| n`)
+		}
+		buf.WriteString(strconv.Itoa(edge.to))
+		buf.WriteByte('\n')
+	}
+
+	if len(foreign) > 0 {
+		buf.WriteString("    classDef foreign stroke-dasharray: 5 5\n")
+		buf.WriteString("    class ")
+		buf.WriteString(strings.Join(foreign, ","))
+		buf.WriteString(" foreign\n")
+	}
+}
+
+// writeMermaidEscaped writes text as part of a quoted Mermaid label, using entity codes for the special characters.
+func writeMermaidEscaped(buf *bytes.Buffer, text string) {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '"':
+			buf.WriteString("#quot;")
+		case '#':
+			buf.WriteString("#35;")
+		case '<':
+			buf.WriteString("#lt;")
+		case '>':
+			buf.WriteString("#gt;")
+		case '\n':
+			buf.WriteString("<br/>")
+		case '\r':
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package erax
+
+import "bytes"
+
+// FormatHTML renders the error tree as self-contained HTML, for web pages like admin panels.
+//
+// Errors follow the same layout as Format: causes are listed one after another,
+// and the errors of WrapWithErrors nodes are nested in collapsible <details> elements.
+// Colors come from CSS classes instead of ANSI sequences, and a <style> element with the current theme is included.
+// The classes are prefixed with "erax-", so the page can override them.
+func FormatHTML(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	var refs nodeRefs
+	if e, isErax := asErax(err); isErax {
+		refs = newNodeRefs(e)
+	}
+
+	buf.WriteString(`<div class="erax-trace">`)
+	buf.WriteByte('\n')
+	writeHTMLStyle(buf)
+	writeHTMLChain(buf, err, 1, &refs)
+	buf.WriteString("</div>\n")
+
+	res := buf.String()
+
+	if buf.Cap() <= 16384 {
+		bufferPool.Put(buf)
+	}
+
+	return res
+}
@@ -0,0 +1,157 @@
+package erax
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// writeHTMLStyle writes the <style> element coloring the trace with the current theme.
+func writeHTMLStyle(buf *bytes.Buffer) {
+	buf.WriteString("<style>\n")
+	buf.WriteString(".erax-trace{font-family:monospace;line-height:1.4}\n")
+	buf.WriteString(".erax-trace ul{list-style:none;margin:0;padding-left:1.5em;border-left:1px solid " + cssColor(branchColor) + "}\n")
+	buf.WriteString(".erax-trace>ul{padding-left:.5em}\n")
+	buf.WriteString(".erax-trace summary{cursor:pointer}\n")
+	buf.WriteString(".erax-message{white-space:pre-wrap;color:" + cssColor(errorColor) + "}\n")
+	buf.WriteString(".erax-foreign>.erax-message{color:" + cssColor(alienColor) + "}\n")
+	buf.WriteString(".erax-meta{margin:0 0 0 1.5em;display:grid;grid-template-columns:max-content auto;column-gap:1ch}\n")
+	buf.WriteString(".erax-meta dt{color:" + cssColor(keyColor) + "}\n")
+	buf.WriteString(".erax-meta dd{margin:0;white-space:pre-wrap;color:" + cssColor(valueColor) + "}\n")
+	buf.WriteString(".erax-label,.erax-ref,.erax-elision{color:" + cssColor(branchColor) + "}\n")
+	buf.WriteString("</style>\n")
+}
+
+// writeHTMLChain writes an error followed by its causes as a single list.
+func writeHTMLChain(buf *bytes.Buffer, err error, depth int, refs *nodeRefs) {
+	buf.WriteString(`<ul class="erax-chain">`)
+	buf.WriteByte('\n')
+
+	for err != nil {
+		err = writeHTMLError(buf, err, depth, refs)
+		depth++
+	}
+
+	buf.WriteString("</ul>\n")
+}
+
+// writeHTMLError writes a single error as a list item. Returns its cause, which is written after it in the same list.
+func writeHTMLError(buf *bytes.Buffer, err error, depth int, refs *nodeRefs) error {
+	e, isErax := asErax(err)
+	if !isErax {
+		buf.WriteString(`<li class="erax-node erax-foreign">`)
+		writeHTMLMessage(buf, fmt.Sprintf("%+v", err))
+		buf.WriteString("</li>\n")
+		return nil
+	}
+
+	if ref := refs.seen(e); ref != nil {
+		buf.WriteString(`<li class="erax-node">`)
+		writeHTMLMessage(buf, e.msg)
+		buf.WriteString(` <a class="erax-ref" href="#erax-`)
+		buf.WriteString(ref.id[1:])
+		buf.WriteString(`">`)
+		buf.WriteString(ref.backRef())
+		buf.WriteString("</a></li>\n")
+		return nil
+	}
+
+	ref := refs.enter(e)
+	defer refs.leave(ref)
+
+	buf.WriteString(`<li class="erax-node">`)
+
+	hasChildren := e.cause != nil || len(e.errs) > 0
+	if maxDepth > 0 && depth >= maxDepth && hasChildren {
+		writeHTMLHeading(buf, e, ref)
+		writeHTMLMeta(buf, e.meta)
+		buf.WriteString(`<ul class="erax-chain">`)
+		writeHTMLElision(buf, deeperLevelsMarker(treeHeight(e)-1))
+		buf.WriteString("</ul></li>\n")
+		return nil
+	}
+
+	if len(e.errs) == 0 {
+		writeHTMLHeading(buf, e, ref)
+		writeHTMLMeta(buf, e.meta)
+		buf.WriteString("</li>\n")
+		return e.cause
+	}
+
+	buf.WriteString("<details open><summary>")
+	writeHTMLHeading(buf, e, ref)
+	buf.WriteString("</summary>")
+	writeHTMLMeta(buf, e.meta)
+	buf.WriteString(`<ul class="erax-errors">`)
+	buf.WriteByte('\n')
+
+	shown, hidden := limitChildren(len(e.errs))
+	for _, ue := range e.errs[:shown] {
+		buf.WriteString("<li>")
+		writeHTMLChain(buf, ue, depth+1, refs)
+		buf.WriteString("</li>\n")
+	}
+	if hidden > 0 {
+		writeHTMLElision(buf, moreErrorsMarker(hidden))
+	}
+
+	buf.WriteString("</ul></details></li>\n")
+
+	return e.cause
+}
+
+// writeHTMLHeading writes the label of a node reachable more than once, if it has one, and its message.
+func writeHTMLHeading(buf *bytes.Buffer, e *errorType, ref *nodeRef) {
+	if ref != nil {
+		buf.WriteString(`<span class="erax-label" id="erax-`)
+		buf.WriteString(ref.id[1:])
+		buf.WriteString(`">`)
+		buf.WriteString(ref.id)
+		buf.WriteString("</span> ")
+	}
+
+	writeHTMLMessage(buf, e.msg)
+}
+
+func writeHTMLMessage(buf *bytes.Buffer, msg string) {
+	buf.WriteString(`<span class="erax-message">`)
+	buf.WriteString(html.EscapeString(msg))
+	buf.WriteString("</span>")
+}
+
+// writeHTMLMeta writes metadata fields as a description list.
+func writeHTMLMeta(buf *bytes.Buffer, meta []MetaField) {
+	if len(meta) == 0 {
+		return
+	}
+
+	buf.WriteString(`<dl class="erax-meta">`)
+	for _, field := range meta {
+		buf.WriteString("<dt>")
+		buf.WriteString(html.EscapeString(field.Key))
+		buf.WriteString("</dt><dd>")
+		buf.WriteString(html.EscapeString(field.Value))
+		buf.WriteString("</dd>")
+	}
+	buf.WriteString("</dl>")
+}
+
+// writeHTMLElision writes a marker item standing in for the part of the tree that was left out.
+func writeHTMLElision(buf *bytes.Buffer, text string) {
+	buf.WriteString(`<li class="erax-elision">`)
+	buf.WriteString(html.EscapeString(text))
+	buf.WriteString("</li>\n")
+}
+
+// cssColor converts a theme color to a CSS color.
+//
+// Hex colors are used as is. ANSI color numbers have no CSS equivalent, so they fall back to the inherited color.
+func cssColor(color lipgloss.Color) string {
+	if strings.HasPrefix(string(color), "#") {
+		return string(color)
+	}
+	return "inherit"
+}
@@ -0,0 +1,139 @@
+package erax
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var (
+	editorURL = ""
+	docsURL   = ""
+)
+
+// SetEditorURL sets the URL template for source locations in formatted output, for example "vscode://file/{path}:{line}".
+//
+// When set, the values of the "file" and "line" metadata fields become terminal hyperlinks (OSC 8),
+// so clicking them opens the location in the editor. The line can also be a part of the file, as in "main.go:42".
+// If the line is unknown, {line} is replaced with 1. Pass an empty string to disable the links (the default).
+func SetEditorURL(template string) {
+	editorURL = template
+}
+
+// SetDocsURL sets the URL template for error codes in formatted output, for example "https://example.com/errors/{code}".
+//
+// When set, the value of the "code" metadata field becomes a terminal hyperlink (OSC 8) to its documentation page.
+// Pass an empty string to disable the links (the default).
+func SetDocsURL(template string) {
+	docsURL = template
+}
+
+// hyperlinksEnabled reports whether metadata values should be rendered as hyperlinks.
+//
+// Hyperlinks are escape sequences, so they are written whenever the trace is colored: they follow the color profile
+// the styles are rendered with, and are never written if NO_COLOR is set.
+func hyperlinksEnabled() bool {
+	if editorURL == "" && docsURL == "" {
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return lipgloss.ColorProfile() != termenv.Ascii
+}
+
+// metaLink returns the URL a metadata field links to, or an empty string if it doesn't link anywhere.
+func metaLink(field *MetaField, meta []MetaField) string {
+	switch field.Key {
+	case "file", "line":
+		if editorURL == "" {
+			return ""
+		}
+		return editorLink(meta)
+	case "code":
+		if docsURL == "" || field.Value == "" {
+			return ""
+		}
+		return strings.ReplaceAll(docsURL, "{code}", url.PathEscape(field.Value))
+	}
+	return ""
+}
+
+// editorLink returns the editor URL of the location described by the "file" and "line" metadata fields.
+func editorLink(meta []MetaField) string {
+	path, line := metaLocation(meta)
+	if path == "" {
+		return ""
+	}
+
+	if line == "" {
+		line = "1"
+	}
+
+	// Absolute paths already start with a slash, so they don't double the one the template puts before them.
+	escaped := (&url.URL{Path: path}).EscapedPath()
+	if strings.HasPrefix(escaped, "/") && strings.Contains(editorURL, "/{path}") {
+		escaped = escaped[1:]
+	}
+
+	link := strings.ReplaceAll(editorURL, "{path}", escaped)
+	return strings.ReplaceAll(link, "{line}", url.PathEscape(line))
+}
+
+// metaLocation returns the source location described by the "file" and "line" metadata fields.
+//
+// The line can also be a part of the file, as in "main.go:42". Returns empty strings for the parts that are unknown.
+func metaLocation(meta []MetaField) (string, string) {
+	var path, line string
+	for i := range meta {
+		switch meta[i].Key {
+		case "file":
+			path = meta[i].Value
+		case "line":
+			line = meta[i].Value
+		}
+	}
+
+	if path != "" && line == "" {
+		path, line = splitLocation(path)
+	}
+
+	return path, line
+}
+
+// splitLocation splits a location like "main.go:42" or "main.go:42:7" into the path and the line.
+//
+// Returns the location itself and an empty line if it doesn't end with a line number.
+func splitLocation(location string) (string, string) {
+	path, line := location, ""
+
+	for i := 0; i < 2; i++ {
+		idx := strings.LastIndexByte(path, ':')
+		if idx == -1 || !isDigits(path[idx+1:]) {
+			break
+		}
+		path, line = path[:idx], path[idx+1:]
+	}
+
+	if line == "" {
+		return location, ""
+	}
+	return path, line
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
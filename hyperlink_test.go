@@ -0,0 +1,32 @@
+package erax
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestFormatWritesHyperlinks(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	SetDocsURL("https://example.com/errors/{code}")
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		SetDocsURL("")
+	})
+
+	t.Setenv("NO_COLOR", "")
+	err := WithMeta(New("db timeout"), "service error", F("code", "500"))
+
+	got := Format(err)
+	if !strings.Contains(got, "\x1b]8;;https://example.com/errors/500") {
+		t.Errorf("Format() has no hyperlink:\n%q", got)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if got := Format(err); strings.Contains(got, "\x1b]8;;") {
+		t.Errorf("Format() has a hyperlink with NO_COLOR set:\n%q", got)
+	}
+}
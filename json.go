@@ -13,33 +13,67 @@ var bufferPool = sync.Pool{
 
 // FormatToJSONMap converts an error to a JSON-compatible map representation. Handles both erax and standard Go errors.
 func FormatToJSONMap(err error) map[string]any {
+	var refs nodeRefs
+	if e, isErax := asErax(err); isErax {
+		refs = newNodeRefs(e)
+	}
+
+	return toJSONMap(err, 1, &refs, rootNodeID())
+}
+
+// FormatToJSONString converts an error to a JSON string representation.
+//
+// Uses a sync.Pool for buffer reuse to improve performance.
+func FormatToJSONString(err error) string {
 	if err == nil {
-		return nil
+		return "{}"
 	}
 
-	if next, isErax := asErax(err); isErax {
-		return errorToMap(next)
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	var refs nodeRefs
+	if e, isErax := asErax(err); isErax {
+		refs = newNodeRefs(e)
 	}
 
-	return map[string]any{
-		"message": err.Error(),
+	writeErrorJSON(buf, err, 1, currentLimits(), &refs, rootNodeID())
+	res := buf.String()
+
+	if buf.Cap() <= 16384 {
+		bufferPool.Put(buf)
 	}
+
+	return res
 }
 
-// FormatToJSONString converts an error to a JSON string representation.
+// FormatJSONPretty converts an error to an indented JSON string representation, for local debugging.
 //
-// Uses a sync.Pool for buffer reuse to improve performance.
-func FormatToJSONString(err error) string {
+// Each nesting level is indented with one more copy of indent. The fields are the same, and in the same order,
+// as the ones of FormatToJSONString. If SetJSONColor is enabled, keys and values are colored with the theme's
+// key and value colors, and the output is no longer valid JSON.
+func FormatJSONPretty(err error, indent string) string {
 	if err == nil {
 		return "{}"
 	}
 
+	src := bufferPool.Get().(*bytes.Buffer)
+	src.Reset()
 	buf := bufferPool.Get().(*bytes.Buffer)
 	buf.Reset()
 
-	writeErrorJSON(buf, err)
+	var refs nodeRefs
+	if e, isErax := asErax(err); isErax {
+		refs = newNodeRefs(e)
+	}
+
+	writeErrorJSON(src, err, 1, currentLimits(), &refs, rootNodeID())
+	writePrettyJSON(buf, src.Bytes(), indent)
 	res := buf.String()
 
+	if src.Cap() <= 16384 {
+		bufferPool.Put(src)
+	}
 	if buf.Cap() <= 16384 {
 		bufferPool.Put(buf)
 	}
@@ -55,5 +89,6 @@ func FromJSONMap(m map[string]any) error {
 		return nil
 	}
 
-	return mapToError(m)
+	var ids nodeIDs
+	return mapToError(m, &ids)
 }
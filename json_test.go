@@ -0,0 +1,66 @@
+package erax
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONEscapesControlCharacters(t *testing.T) {
+	err := WithMeta(New("\x1b[31mdb timeout\x1b[0m\x00"), "service\x7f error", F("code\x01", "500\x1f"))
+
+	for name, out := range map[string]string{
+		"FormatToJSONString": FormatToJSONString(err),
+		"FormatJSONPretty":   FormatJSONPretty(err, "  "),
+	} {
+		if !json.Valid([]byte(out)) {
+			t.Errorf("%s returned invalid JSON:\n%s", name, out)
+		}
+	}
+
+	var m map[string]any
+	if e := json.Unmarshal([]byte(FormatToJSONString(err)), &m); e != nil {
+		t.Fatal(e)
+	}
+	if got := m["cause"].(map[string]any)["message"]; got != "\x1b[31mdb timeout\x1b[0m\x00" {
+		t.Errorf("message = %q, want it unchanged", got)
+	}
+}
+
+func TestJSONPathIDs(t *testing.T) {
+	SetNodeIDs(true)
+	t.Cleanup(func() { SetNodeIDs(false) })
+
+	err := WithMeta(New("db timeout"), "service error", F("code", "500"))
+
+	m := FormatToJSONMap(err)
+	if m["id"] != "#1" {
+		t.Errorf(`root "id" = %v, want "#1"`, m["id"])
+	}
+	if cause := m["cause"].(map[string]any); cause["id"] != "#2" {
+		t.Errorf(`cause "id" = %v, want "#2"`, cause["id"])
+	}
+	if _, ok := m["anchor"]; ok {
+		t.Errorf(`root has an "anchor" although nothing refers to it`)
+	}
+}
+
+func TestJSONSharedNodes(t *testing.T) {
+	shared := WithMeta(New("db timeout"), "query failed", F("table", "users"))
+	err := WrapWithErrors(nil, "service error", shared, shared)
+
+	var m map[string]any
+	if e := json.Unmarshal([]byte(FormatToJSONString(err)), &m); e != nil {
+		t.Fatal(e)
+	}
+
+	errs := m["cause"].([]any)
+	first, second := errs[0].(map[string]any), errs[1].(map[string]any)
+	if first["anchor"] == nil || second["ref"] != first["anchor"] {
+		t.Errorf(`second error should be a "ref" to the "anchor" of the first one: %v, %v`, first, second)
+	}
+
+	restored, _ := asErax(FromJSONMap(FormatToJSONMap(err)))
+	if restored.errs[0] != restored.errs[1] {
+		t.Errorf("FromJSONMap didn't restore the shared node")
+	}
+}
@@ -3,25 +3,52 @@ package erax
 import "bytes"
 
 // writeErrorJSON writes an error's JSON representation directly to a buffer.
-func writeErrorJSON(buf *bytes.Buffer, err error) {
+//
+// The depth of the root error is 1, and lim holds the depth and children limits.
+// Erax nodes reachable more than once are written only once, labeled with an "anchor",
+// and later occurrences are written as a "ref" to it.
+// If node IDs are enabled, id is the path ID of the error, and every error gets it as "id".
+func writeErrorJSON(buf *bytes.Buffer, err error, depth int, lim limits, refs *nodeRefs, id string) {
 	if err == nil {
 		return
 	}
 
 	buf.WriteString(`{"message":`)
-	writeEscapedString(buf, err.Error())
 
-	if e, ok := err.(*errorType); ok {
-		writeEraxJSONFields(buf, e)
-	} else if e, isErax := asErax(err); isErax {
-		writeEraxJSONFields(buf, e)
+	if e, isErax := asErax(err); isErax {
+		writeEscapedString(buf, e.msg)
+		writeEraxJSONFields(buf, e, depth, lim, refs, id)
+	} else {
+		writeEscapedString(buf, err.Error())
+		if id != "" {
+			writePathJSON(buf, id)
+		}
 	}
 
 	buf.WriteByte('}')
 }
 
 // writeEraxJSONFields writes erax-specific JSON fields (metadata and cause) to a buffer.
-func writeEraxJSONFields(buf *bytes.Buffer, e *errorType) {
+func writeEraxJSONFields(buf *bytes.Buffer, e *errorType, depth int, lim limits, refs *nodeRefs, id string) {
+	if id != "" {
+		writePathJSON(buf, id)
+	}
+
+	if ref := refs.seen(e); ref != nil {
+		buf.WriteString(`,"ref":`)
+		writeEscapedString(buf, ref.id)
+		return
+	}
+
+	ref := refs.enterAt(e, id)
+	defer refs.leave(ref)
+
+	// Only nodes reachable more than once are labeled, so "anchor" always marks a node other nodes refer to.
+	if ref != nil {
+		buf.WriteString(`,"anchor":`)
+		writeEscapedString(buf, ref.id)
+	}
+
 	if len(e.meta) > 0 {
 		buf.WriteString(`,"meta":{`)
 		for i, field := range e.meta {
@@ -41,31 +68,115 @@ func writeEraxJSONFields(buf *bytes.Buffer, e *errorType) {
 	if hasCause || hasErrs {
 		buf.WriteString(`,"cause":`)
 
+		if lim.depth > 0 && depth >= lim.depth {
+			writeElisionJSON(buf, deeperLevelsMarker(treeHeight(e)-1))
+			return
+		}
+
+		shown, hidden := lim.splitChildren(len(e.errs))
+
+		// The errors of a WrapWithErrors node are always a list, even a single one,
+		// so they can be told from a cause the same way in FormatToJSONMap maps.
 		if hasCause && !hasErrs {
-			writeErrorJSON(buf, e.cause)
-		} else if !hasCause && len(e.errs) == 1 {
-			writeErrorJSON(buf, e.errs[0])
+			writeErrorJSON(buf, e.cause, depth+1, lim, refs, causeNodeID(id))
 		} else {
 			buf.WriteByte('[')
 			first := true
 
 			if hasCause {
-				writeErrorJSON(buf, e.cause)
+				writeErrorJSON(buf, e.cause, depth+1, lim, refs, causeNodeID(id))
 				first = false
 			}
 
-			for _, ue := range e.errs {
+			for i, ue := range e.errs[:shown] {
 				if !first {
 					buf.WriteByte(',')
 				}
-				writeErrorJSON(buf, ue)
+				writeErrorJSON(buf, ue, depth+1, lim, refs, childNodeID(id, i))
 				first = false
 			}
+
+			if hidden > 0 {
+				buf.WriteByte(',')
+				writeElisionJSON(buf, moreErrorsMarker(hidden))
+			}
 			buf.WriteByte(']')
 		}
 	}
 }
 
+// writeElisionJSON writes a marker object standing in for the part of the tree that was left out.
+func writeElisionJSON(buf *bytes.Buffer, text string) {
+	buf.WriteString(`{"message":`)
+	writeEscapedString(buf, text)
+	buf.WriteByte('}')
+}
+
+// writePrettyJSON writes the compact JSON src to a buffer, indented with indent and colored if SetJSONColor is enabled.
+func writePrettyJSON(buf *bytes.Buffer, src []byte, indent string) {
+	level := 0
+
+	newline := func() {
+		buf.WriteByte('\n')
+		for i := 0; i < level; i++ {
+			buf.WriteString(indent)
+		}
+	}
+
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; c {
+		case '"':
+			end := jsonStringEnd(src, i)
+			token := string(src[i:end])
+
+			if !jsonColor {
+				buf.WriteString(token)
+			} else if end < len(src) && src[end] == ':' {
+				buf.WriteString(keyText.Render(token))
+			} else {
+				buf.WriteString(valueText.Render(token))
+			}
+
+			i = end - 1
+		case '{', '[':
+			if i+1 < len(src) && (src[i+1] == '}' || src[i+1] == ']') {
+				buf.WriteByte(c)
+				buf.WriteByte(src[i+1])
+				i++
+				continue
+			}
+
+			buf.WriteByte(c)
+			level++
+			newline()
+		case '}', ']':
+			level--
+			newline()
+			buf.WriteByte(c)
+		case ',':
+			buf.WriteByte(c)
+			newline()
+		case ':':
+			buf.WriteString(": ")
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+// jsonStringEnd returns the index right after the JSON string starting at src[start].
+func jsonStringEnd(src []byte, start int) int {
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
 // writeEscapedString writes a string to a buffer with JSON escaping applied.
 func writeEscapedString(b *bytes.Buffer, s string) {
 	b.WriteByte('"')
@@ -73,7 +184,7 @@ func writeEscapedString(b *bytes.Buffer, s string) {
 	lenS := len(s)
 	for i := 0; i < lenS; i++ {
 		c := s[i]
-		if c == '"' || c == '\\' || c == '\n' || c == '\r' || c == '\t' {
+		if c == '"' || c == '\\' || c < 0x20 {
 			if i > last {
 				b.WriteString(s[last:i])
 			}
@@ -85,8 +196,13 @@ func writeEscapedString(b *bytes.Buffer, s string) {
 				b.WriteByte('r')
 			case '\t':
 				b.WriteByte('t')
-			default:
+			case '"', '\\':
 				b.WriteByte(c)
+			default:
+				// Other control characters, like the ESC of colored text, are only valid in JSON as \u escapes.
+				b.WriteString("u00")
+				b.WriteByte(hexDigits[c>>4])
+				b.WriteByte(hexDigits[c&0xf])
 			}
 			last = i + 1
 		}
@@ -96,3 +212,12 @@ func writeEscapedString(b *bytes.Buffer, s string) {
 	}
 	b.WriteByte('"')
 }
+
+// hexDigits are the digits of the \u escapes written by writeEscapedString.
+const hexDigits = "0123456789abcdef"
+
+// writePathJSON writes the path ID of an error as its "id" field.
+func writePathJSON(buf *bytes.Buffer, id string) {
+	buf.WriteString(`,"id":`)
+	writeEscapedString(buf, "#"+id)
+}
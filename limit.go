@@ -0,0 +1,204 @@
+package erax
+
+import "strconv"
+
+var (
+	maxDepth    = 0
+	maxChildren = 0
+	maxLines    = 0
+
+	maxCastDepth = 0
+	maxCastNodes = 0
+)
+
+// castTruncatedMarker is the message of the node replacing the errors past the limits of Cast.
+const castTruncatedMarker = "… truncated"
+
+// SetMaxDepth limits how deep the error tree is rendered by Format and encoded to JSON.
+//
+// Everything below the limit collapses into a single "… N deeper levels" marker.
+// The root error is at depth 1. Pass 0 to disable the limit (the default).
+func SetMaxDepth(depth int) {
+	maxDepth = depth
+}
+
+// SetMaxChildren limits how many errors of a single WrapWithErrors node are rendered by Format and encoded to JSON.
+//
+// The remaining errors collapse into a single "… N more errors" marker.
+// Pass 0 to disable the limit (the default).
+func SetMaxChildren(children int) {
+	maxChildren = children
+}
+
+// SetMaxLines limits the total number of lines of the trace rendered by Format.
+//
+// The remaining lines are dropped and replaced with a single "… N more lines" marker.
+// Pass 0 to disable the limit (the default).
+func SetMaxLines(lines int) {
+	maxLines = lines
+}
+
+// SetMaxCastDepth limits how deep a standard Go error tree is converted by Cast, WrapCast and WrapWithErrorsCast.
+//
+// Errors below the limit are replaced with a "… truncated" marker. errors.As and errors.Is still search them through it,
+// so the limit doesn't change what they find. The root error is at depth 1. Pass 0 to disable the limit (the default).
+func SetMaxCastDepth(depth int) {
+	maxCastDepth = depth
+}
+
+// SetMaxCastNodes limits how many errors of a standard Go error tree are converted by Cast, WrapCast and WrapWithErrorsCast.
+//
+// Errors past the limit are replaced with a "… truncated" marker, a single one for the remaining errors of a join,
+// so huge foreign trees don't take up as much memory again. errors.As and errors.Is still search them through it.
+// Pass 0 to disable the limit (the default).
+func SetMaxCastNodes(nodes int) {
+	maxCastNodes = nodes
+}
+
+// limits holds the depth, children and lines limits a tree is rendered with. Zero disables a limit.
+type limits struct {
+	depth, children, lines int
+}
+
+// currentLimits returns the limits set by SetMaxDepth, SetMaxChildren and SetMaxLines.
+func currentLimits() limits {
+	return limits{depth: maxDepth, children: maxChildren, lines: maxLines}
+}
+
+// limitChildren splits the number of child errors into the number of shown and hidden ones.
+func limitChildren(n int) (shown, hidden int) {
+	return currentLimits().splitChildren(n)
+}
+
+// splitChildren splits the number of child errors into the number of shown and hidden ones.
+func (l limits) splitChildren(n int) (shown, hidden int) {
+	if l.children > 0 && n > l.children {
+		return l.children, n - l.children
+	}
+	return n, 0
+}
+
+// treeHeight returns the number of levels of the error tree, counting the error itself.
+//
+// Non-erax errors are counted as leaves, the same way they are rendered.
+// So are nodes reached more than once.
+func treeHeight(err error) int {
+	var seen visitSet
+	return subtreeHeight(err, &seen)
+}
+
+func subtreeHeight(err error, seen *visitSet) int {
+	e, isErax := asErax(err)
+	if !isErax || !seen.add(e) {
+		return 1
+	}
+
+	height := 0
+	for _, ue := range e.errs {
+		if h := subtreeHeight(ue, seen); h > height {
+			height = h
+		}
+	}
+
+	if e.cause != nil {
+		if h := subtreeHeight(e.cause, seen); h > height {
+			height = h
+		}
+	}
+
+	return height + 1
+}
+
+// limitLines returns the part of s that still fits under the line limit.
+//
+// Everything past the limit is dropped, and the dropped lines are counted.
+func (tw *traceWriter) limitLines(s string) string {
+	if tw.lines >= tw.limits.lines {
+		tw.hide(s)
+		return ""
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\n' {
+			continue
+		}
+
+		tw.lines++
+		if tw.lines >= tw.limits.lines {
+			tw.hide(s[i:])
+			return s[:i]
+		}
+	}
+
+	return s
+}
+
+// hide counts the lines of the dropped output s.
+func (tw *traceWriter) hide(s string) {
+	if len(s) == 0 {
+		return
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			tw.hiddenLines++
+		}
+	}
+	tw.lastHidden = s[len(s)-1]
+}
+
+// writeHiddenLines writes the marker for the lines dropped because of the line limit, if there are any.
+func (tw *traceWriter) writeHiddenLines() {
+	hidden := tw.hiddenLines
+	if tw.lastHidden == '\n' {
+		hidden--
+	}
+
+	if hidden <= 0 {
+		return
+	}
+
+	_ = tw.Writer.WriteByte('\n')
+	_ = tw.Writer.WriteByte(' ')
+	_, _ = tw.Writer.WriteString(elisionText.Render(moreLinesMarker(hidden)))
+}
+
+func deeperLevelsMarker(n int) string {
+	return elisionMarker(n, "deeper level", "deeper levels")
+}
+
+func moreErrorsMarker(n int) string {
+	return elisionMarker(n, "more error", "more errors")
+}
+
+func moreLinesMarker(n int) string {
+	return elisionMarker(n, "more line", "more lines")
+}
+
+// elisionMarker returns a marker like "… 1,234 more errors".
+func elisionMarker(n int, singular, plural string) string {
+	noun := plural
+	if n == 1 {
+		noun = singular
+	}
+
+	return "… " + formatCount(n) + " " + noun
+}
+
+// formatCount formats a non-negative number with thousands separators.
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	if len(s) <= 3 {
+		return s
+	}
+
+	b := make([]byte, 0, len(s)+(len(s)-1)/3)
+	for i := 0; i < len(s); i++ {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b = append(b, ',')
+		}
+		b = append(b, s[i])
+	}
+
+	return string(b)
+}
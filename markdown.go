@@ -0,0 +1,30 @@
+package erax
+
+import "bytes"
+
+// FormatMarkdown renders the error tree as nested Markdown lists, for incident docs and PR comments.
+//
+// Errors follow the same layout as Format: causes are listed one after another,
+// and the errors of WrapWithErrors nodes are nested one level deeper. Metadata is shown as inline code.
+func FormatMarkdown(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	var refs nodeRefs
+	if e, isErax := asErax(err); isErax {
+		refs = newNodeRefs(e)
+	}
+
+	writeMarkdownError(buf, err, 0, 0, 1, &refs)
+	res := buf.String()
+
+	if buf.Cap() <= 16384 {
+		bufferPool.Put(buf)
+	}
+
+	return res
+}
@@ -0,0 +1,138 @@
+package erax
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// markdownSpecials are the characters escaped in Markdown text.
+const markdownSpecials = "\\`*_[]<>#|~"
+
+// writeMarkdownError writes an error as a list item at the given level, followed by its causes at causeLevel.
+//
+// The errors of a WrapWithErrors node are nested one level deeper, each with its causes one more level deeper,
+// so every error of the group stays a single item.
+func writeMarkdownError(buf *bytes.Buffer, err error, level, causeLevel, depth int, refs *nodeRefs) {
+	writeMarkdownItem(buf, level)
+
+	e, isErax := asErax(err)
+	if !isErax {
+		writeMarkdownText(buf, fmt.Sprintf("%+v", err), level)
+		buf.WriteByte('\n')
+		return
+	}
+
+	if ref := refs.seen(e); ref != nil {
+		writeMarkdownText(buf, e.msg, level)
+		buf.WriteString(" _")
+		buf.WriteString(ref.backRef())
+		buf.WriteString("_\n")
+		return
+	}
+
+	if ref := refs.enter(e); ref != nil {
+		defer refs.leave(ref)
+		buf.WriteString("**")
+		buf.WriteString(ref.id)
+		buf.WriteString("** ")
+	}
+
+	writeMarkdownText(buf, e.msg, level)
+	for i, field := range e.meta {
+		if i > 0 || e.msg != "" {
+			buf.WriteByte(' ')
+		}
+		writeMarkdownCode(buf, field.Key+"="+field.Value)
+	}
+	buf.WriteByte('\n')
+
+	if e.cause == nil && len(e.errs) == 0 {
+		return
+	}
+
+	if maxDepth > 0 && depth >= maxDepth {
+		writeMarkdownElision(buf, level+1, deeperLevelsMarker(treeHeight(e)-1))
+		return
+	}
+
+	shown, hidden := limitChildren(len(e.errs))
+	for _, ue := range e.errs[:shown] {
+		writeMarkdownError(buf, ue, level+1, level+2, depth+1, refs)
+	}
+	if hidden > 0 {
+		writeMarkdownElision(buf, level+1, moreErrorsMarker(hidden))
+	}
+
+	if e.cause != nil {
+		writeMarkdownError(buf, e.cause, causeLevel, causeLevel, depth+1, refs)
+	}
+}
+
+// writeMarkdownItem starts a list item at the given level.
+func writeMarkdownItem(buf *bytes.Buffer, level int) {
+	for i := 0; i < level; i++ {
+		buf.WriteString("  ")
+	}
+	buf.WriteString("- ")
+}
+
+// writeMarkdownElision writes a marker item standing in for the part of the tree that was left out.
+func writeMarkdownElision(buf *bytes.Buffer, level int, text string) {
+	writeMarkdownItem(buf, level)
+	buf.WriteByte('_')
+	buf.WriteString(text)
+	buf.WriteString("_\n")
+}
+
+// writeMarkdownText writes escaped text, indenting the following lines so they stay in the list item.
+func writeMarkdownText(buf *bytes.Buffer, text string, level int) {
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		if c == '\n' {
+			buf.WriteByte('\n')
+			for j := 0; j <= level; j++ {
+				buf.WriteString("  ")
+			}
+			continue
+		}
+
+		if indexByte(markdownSpecials, c) != -1 {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(c)
+	}
+}
+
+// writeMarkdownCode writes text as inline code, using a fence longer than any run of backticks in it.
+//
+// Inline code can't span lines, so line breaks are shown as "↵".
+func writeMarkdownCode(buf *bytes.Buffer, text string) {
+	text = strings.ReplaceAll(text, "\n", "↵")
+
+	run, longest := 0, 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != '`' {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+
+	fence := strings.Repeat("`", longest+1)
+	pad := longest > 0
+
+	buf.WriteString(fence)
+	if pad {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(text)
+	if pad {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(fence)
+}
@@ -58,6 +58,7 @@ func AddMeta(err error, message string, key, value string) error {
 // GetMeta searches for a metadata field by key across the entire error chain.
 //
 // It searches from the most recent error backwards through causes and children.
+// Errors reachable more than once are searched only once, so cyclic chains are safe.
 func GetMeta(err error, key string) (string, bool) {
 	if err == nil {
 		return "", false
@@ -66,11 +67,13 @@ func GetMeta(err error, key string) (string, bool) {
 	stack := [8]error{err}
 	slice := stack[:1]
 
+	var seen visitSet
+
 	for len(slice) > 0 {
 		current := slice[len(slice)-1]
 		slice = slice[:len(slice)-1]
 
-		if current == nil {
+		if current == nil || !seen.add(current) {
 			continue
 		}
 
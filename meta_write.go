@@ -1,9 +1,12 @@
 package erax
 
-import "strings"
+import "github.com/charmbracelet/x/ansi"
 
-// writeMeta formats and writes metadata fields to a string builder with proper indentation.
-func writeMeta(sb *strings.Builder, meta []MetaField, isNested bool, levels []bool) {
+// writeMeta formats and writes metadata fields to the trace writer with proper indentation.
+//
+// Every field starts on a new line, and the last one is not terminated, so the caller decides what follows it.
+// If isEnd is set, the node has no children, so the guide leading down to them is left out.
+func writeMeta(tw *traceWriter, meta []MetaField, isNested, isEnd bool, levels []bool) {
 	metaLen := len(meta)
 	if metaLen == 0 {
 		return
@@ -16,43 +19,83 @@ func writeMeta(sb *strings.Builder, meta []MetaField, isNested bool, levels []bo
 
 	isLastLevel := len(levels) > 0 && levels[len(levels)-1]
 
+	keyWidth := 0
+	if tw.align {
+		for i := 0; i < metaLen; i++ {
+			if w := ansi.StringWidth(meta[i].Key); w > keyWidth {
+				keyWidth = w
+			}
+		}
+	}
+
 	for i := 0; i < metaLen; i++ {
 		field := &meta[i]
 		isLastPair := i == metaLen-1
-		writeIndent(sb, childLevels)
-
-		if isLastLevel {
-			sb.WriteByte(' ')
-			sb.WriteString(branchMid)
-			sb.WriteByte(' ')
+		tw.WriteByte('\n')
+		writeIndent(tw, childLevels)
+
+		if isLastLevel && isEnd {
+			tw.WriteString("    ")
+		} else if isLastLevel {
+			tw.WriteByte(' ')
+			tw.WriteString(branchMid)
+			tw.WriteByte(' ')
+		} else if isNested && isEnd {
+			tw.WriteString(branchMid)
+			tw.WriteString("  ")
 		} else if isNested {
-			sb.WriteString(branchTwix)
+			tw.WriteString(branchTwix)
 		} else {
-			sb.WriteString(branchMid)
-			sb.WriteString("  ")
+			tw.WriteString(branchMid)
+			tw.WriteString("  ")
 		}
-		sb.WriteString("  ")
+		tw.WriteString("  ")
 		if isLastPair {
-			sb.WriteString(branchEnd)
+			tw.WriteString(branchEnd)
 		} else {
-			sb.WriteString(branchNext)
+			tw.WriteString(branchNext)
+		}
+
+		tw.WriteString(keyText.Render(field.Key))
+		tw.WriteString(": ")
+
+		valueIndent := 0
+		if tw.align {
+			writeSpaces(tw, keyWidth-ansi.StringWidth(field.Key))
+			valueIndent = keyWidth + 1
 		}
 
-		sb.WriteString(keyText.Render(field.Key))
-		sb.WriteString(": ")
-		writeValue(sb, field.Value, isLastPair, isNested, levels)
-		sb.WriteByte('\n')
+		// Only values rendered on a single line are linked, after wrapping too,
+		// so the branches of the following lines stay plain text.
+		link := ""
+		if tw.links && indexByte(field.Value, '\n') == -1 && !tw.overflows(field.Value) {
+			link = metaLink(field, meta)
+		}
+
+		if link == "" {
+			writeValue(tw, field.Value, isLastPair, isNested, isEnd, levels, valueIndent)
+			continue
+		}
+
+		tw.WriteString(ansi.SetHyperlink(link))
+		writeValue(tw, field.Value, isLastPair, isNested, isEnd, levels, valueIndent)
+		tw.WriteString(ansi.ResetHyperlink())
 	}
 }
 
 // writeValue formats and writes a metadata value, handling multi-line values with proper indentation.
-func writeValue(sb *strings.Builder, text string, isLastPair, isNested bool, levels []bool) {
-	if indexByte(text, '\n') == -1 {
-		sb.WriteString(valueText.Render(text))
+//
+// A non-zero indent means the value is aligned: it starts right after the key,
+// and the following lines are shifted by indent columns to stay under the first one.
+func writeValue(tw *traceWriter, text string, isLastPair, isNested, isEnd bool, levels []bool, indent int) {
+	if indexByte(text, '\n') == -1 && !tw.overflows(text) {
+		tw.WriteString(valueText.Render(text))
 		return
 	}
 
-	sb.WriteByte('\n')
+	if indent == 0 {
+		tw.WriteByte('\n')
+	}
 	start := 0
 	lineIdx := 0
 	textLen := len(text)
@@ -64,10 +107,11 @@ func writeValue(sb *strings.Builder, text string, isLastPair, isNested bool, lev
 
 	isLastLevel := len(levels) > 0 && levels[len(levels)-1]
 
-	for start < textLen {
-		idx := indexByte(text[start:], '\n')
-		var line string
-		if idx == -1 {
+	var line, rest string
+	for start < textLen || rest != "" {
+		if rest != "" {
+			line = rest
+		} else if idx := indexByte(text[start:], '\n'); idx == -1 {
 			line = text[start:]
 			start = textLen
 		} else {
@@ -76,32 +120,41 @@ func writeValue(sb *strings.Builder, text string, isLastPair, isNested bool, lev
 		}
 
 		if lineIdx > 0 {
-			sb.WriteByte('\n')
+			tw.WriteByte('\n')
 		}
 
-		writeIndent(sb, childLevels)
-
-		if isLastLevel {
-			sb.WriteByte(' ')
-			sb.WriteString(branchMid)
-			sb.WriteByte(' ')
-		} else if isNested {
-			sb.WriteString(branchTwix)
-		} else {
-			sb.WriteString(branchMid)
-			sb.WriteString("  ")
-		}
-		sb.WriteByte(' ')
-
-		if isLastPair {
-			sb.WriteString("   ")
-		} else {
-			sb.WriteString(branchMid)
-			sb.WriteByte(' ')
+		if lineIdx > 0 || indent == 0 {
+			writeIndent(tw, childLevels)
+
+			if isLastLevel && isEnd {
+				tw.WriteString("    ")
+			} else if isLastLevel {
+				tw.WriteByte(' ')
+				tw.WriteString(branchMid)
+				tw.WriteByte(' ')
+			} else if isNested && isEnd {
+				tw.WriteString(branchMid)
+				tw.WriteString("  ")
+			} else if isNested {
+				tw.WriteString(branchTwix)
+			} else {
+				tw.WriteString(branchMid)
+				tw.WriteString("  ")
+			}
+			tw.WriteByte(' ')
+
+			if isLastPair {
+				tw.WriteString("   ")
+			} else {
+				tw.WriteString(branchMid)
+				tw.WriteByte(' ')
+			}
+			tw.WriteString("  ")
+			writeSpaces(tw, indent)
 		}
-		sb.WriteString("  ")
 
-		sb.WriteString(valueText.Render(line))
+		line, rest = tw.fit(line)
+		tw.WriteString(valueText.Render(line))
 		lineIdx++
 	}
 }
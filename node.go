@@ -0,0 +1,116 @@
+package erax
+
+// Node is a read-only view of a single error of the tree, for renderers and exporters outside the package.
+//
+// Erax errors implement Node themselves, so walking a tree through Node doesn't convert it to anything.
+// Non-erax errors are wrapped into foreign nodes, with their Error() as the message and no metadata.
+// The cause of a foreign node is the error returned by its Unwrap() error method,
+// and its errors are the ones returned by its Unwrap() []error method.
+type Node interface {
+	// Message returns the message of the error.
+	Message() string
+	// Meta returns the metadata fields of the error. The slice is shared with the error and must not be modified.
+	Meta() []MetaField
+	// Cause returns the cause of the error, or nil if it has none.
+	Cause() Node
+	// Errors returns the errors of a WrapWithErrors node, or nil if it has none.
+	Errors() []Node
+	// IsForeign reports whether the error is a non-erax error.
+	IsForeign() bool
+	// Err returns the error the node is a view of.
+	Err() error
+}
+
+// Inspect returns a read-only view of the error tree, or nil for a nil error.
+func Inspect(err error) Node {
+	if err == nil {
+		return nil
+	}
+
+	if e, isErax := asErax(err); isErax {
+		return e
+	}
+
+	return foreignNode{err: err}
+}
+
+// Message returns the message of the error.
+//
+// This implements Node.
+func (e *errorType) Message() string { return e.msg }
+
+// Meta returns the metadata fields of the error.
+//
+// This implements Node.
+func (e *errorType) Meta() []MetaField { return e.meta }
+
+// Cause returns the cause of the error, or nil if it has none.
+//
+// This implements Node.
+func (e *errorType) Cause() Node { return Inspect(e.cause) }
+
+// Errors returns the errors of a WrapWithErrors node, or nil if it has none. Nil errors are left out.
+//
+// This implements Node.
+func (e *errorType) Errors() []Node {
+	if len(e.errs) == 0 {
+		return nil
+	}
+
+	nodes := make([]Node, 0, len(e.errs))
+	for _, ue := range e.errs {
+		if ue != nil {
+			nodes = append(nodes, Inspect(ue))
+		}
+	}
+
+	return nodes
+}
+
+// IsForeign always returns false for erax errors.
+//
+// This implements Node.
+func (e *errorType) IsForeign() bool { return false }
+
+// Err returns the error itself.
+//
+// This implements Node.
+func (e *errorType) Err() error { return e }
+
+// foreignNode is the Node view of a non-erax error.
+type foreignNode struct {
+	err error
+}
+
+func (n foreignNode) Message() string   { return n.err.Error() }
+func (n foreignNode) Meta() []MetaField { return nil }
+func (n foreignNode) IsForeign() bool   { return true }
+func (n foreignNode) Err() error        { return n.err }
+
+func (n foreignNode) Cause() Node {
+	if w, ok := n.err.(interface{ Unwrap() error }); ok {
+		return Inspect(w.Unwrap())
+	}
+	return nil
+}
+
+func (n foreignNode) Errors() []Node {
+	w, ok := n.err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil
+	}
+
+	errs := w.Unwrap()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	nodes := make([]Node, 0, len(errs))
+	for _, ue := range errs {
+		if ue != nil {
+			nodes = append(nodes, Inspect(ue))
+		}
+	}
+
+	return nodes
+}
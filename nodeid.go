@@ -0,0 +1,101 @@
+package erax
+
+import (
+	"strconv"
+	"strings"
+)
+
+var showNodeIDs = false
+
+// SetNodeIDs enables or disables prefixing every error rendered by Format with its path ID, like "#1.2.3".
+//
+// The same IDs are added as "id" fields by the JSON encoders, and back-references point to them.
+// An ID can be passed to NodeAt to retrieve the error it belongs to.
+//
+// IDs follow the layout of Format: the errors of a chain of causes are numbered 1, 2, 3 and so on,
+// and the errors of a WrapWithErrors node start new chains below it. So "#1.2.3" is the third error
+// of the chain started by the second error of the first error of the tree.
+func SetNodeIDs(enabled bool) {
+	showNodeIDs = enabled
+}
+
+// NodeAt returns the error at the given path ID, like "1.2.3" or "#1.2.3", or nil if there is none.
+//
+// See SetNodeIDs for how the IDs are assigned.
+func NodeAt(err error, id string) error {
+	if err == nil {
+		return nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(id, "#"), ".")
+	if len(parts)%2 == 0 {
+		return nil
+	}
+
+	current := err
+	for i, part := range parts {
+		n, convErr := strconv.Atoi(part)
+		if convErr != nil || n < 1 {
+			return nil
+		}
+
+		e, isErax := asErax(current)
+
+		// Odd parts pick an error of a WrapWithErrors node, even ones an error of the chain it starts.
+		if i%2 == 1 {
+			if !isErax || n > len(e.errs) {
+				return nil
+			}
+			current = e.errs[n-1]
+			continue
+		}
+
+		for ; n > 1; n-- {
+			if e, isErax = asErax(current); !isErax || e.cause == nil {
+				return nil
+			}
+			current = e.cause
+		}
+	}
+
+	return current
+}
+
+// rootNodeID returns the ID of the root error, or an empty string if IDs are disabled.
+func rootNodeID() string {
+	if !showNodeIDs {
+		return ""
+	}
+	return "1"
+}
+
+// causeNodeID returns the ID of the cause of the error with the given ID: the next error of the same chain.
+func causeNodeID(id string) string {
+	if id == "" {
+		return ""
+	}
+
+	idx := strings.LastIndexByte(id, '.')
+	n, _ := strconv.Atoi(id[idx+1:])
+
+	return id[:idx+1] + strconv.Itoa(n+1)
+}
+
+// childNodeID returns the ID of the i-th error of the WrapWithErrors node with the given ID: the first error of a new chain.
+func childNodeID(id string, i int) string {
+	if id == "" {
+		return ""
+	}
+	return id + "." + strconv.Itoa(i+1) + ".1"
+}
+
+// nodeLabel returns the label an error is rendered with: the label of a node reachable more than once, or its ID.
+func nodeLabel(ref *nodeRef, id string) string {
+	if ref != nil {
+		return ref.id
+	}
+	if id != "" {
+		return "#" + id
+	}
+	return ""
+}
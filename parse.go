@@ -0,0 +1,141 @@
+package erax
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Parse reconstructs an erax error tree from a rendered trace.
+//
+// It accepts both the output of Format and the single-line output of FormatCompact,
+// so errors can be re-inspected, re-serialized or compared when all that's left of them is a log line.
+// ANSI sequences (colors and hyperlinks) are stripped automatically.
+//
+// Messages, metadata and the shape of the tree are restored. Some details of the original tree are lost,
+// for example wrapped lines become multi-line messages, and non-erax errors become plain errors.
+func Parse(text string) (error, error) {
+	text = ansi.Strip(text)
+
+	if strings.Contains(text, traceHeader) || indexByte(text, '\n') != -1 {
+		return ParseTrace(text)
+	}
+
+	return ParseCompact(text)
+}
+
+// ParseTrace reconstructs an erax error tree from the output of Format.
+//
+// ANSI sequences are stripped automatically.
+func ParseTrace(text string) (error, error) {
+	text = ansi.Strip(text)
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	p := traceParser{}
+	return p.parse(text)
+}
+
+// ParseCompact reconstructs an erax error tree from the output of FormatCompact.
+//
+// ANSI sequences are stripped automatically.
+func ParseCompact(text string) (error, error) {
+	text = ansi.Strip(strings.TrimRight(text, "\r\n"))
+	if text == "" {
+		return nil, nil
+	}
+
+	p := compactParser{s: text}
+	return p.parse()
+}
+
+// parsedNode is an error being reconstructed by a parser.
+type parsedNode struct {
+	e     *errorType
+	cause *parsedNode
+	errs  []*parsedNode
+	// ref is set for back-references, which stand for a node that was already parsed.
+	ref *errorType
+	// labeled is set for nodes reachable more than once, which must stay erax nodes.
+	labeled bool
+}
+
+// build converts a parsed node to an error.
+//
+// Plain leaves become standard errors, the same way FromJSONMap restores them.
+func (n *parsedNode) build() error {
+	if n.ref != nil {
+		return n.ref
+	}
+
+	if n.cause == nil && len(n.errs) == 0 && len(n.e.meta) == 0 && !n.labeled {
+		return errors.New(n.e.msg)
+	}
+
+	if n.cause != nil {
+		n.e.cause = n.cause.build()
+	}
+
+	if len(n.errs) > 0 {
+		n.e.errs = make([]error, len(n.errs))
+		for i, child := range n.errs {
+			n.e.errs[i] = child.build()
+		}
+	}
+
+	return n.e
+}
+
+// parseBackRef parses a back-reference like "(see #1)" or "(cycle to #1)" at the start of s.
+//
+// Returns the label and the length of the back-reference, or an empty label if there is none.
+func parseBackRef(s string) (string, int) {
+	for _, prefix := range [...]string{"(see ", "(cycle to "} {
+		if !strings.HasPrefix(s, prefix+"#") {
+			continue
+		}
+
+		end := strings.IndexByte(s, ')')
+		if end == -1 {
+			return "", 0
+		}
+
+		label := s[len(prefix):end]
+		if !isLabel(label) {
+			return "", 0
+		}
+
+		return label, end + 1
+	}
+
+	return "", 0
+}
+
+// parseLabel parses a label like "#1 " at the start of s.
+//
+// Returns the label and the length of the label with the following space, or an empty label if there is none.
+func parseLabel(s string) (string, int) {
+	end := strings.IndexByte(s, ' ')
+	if end == -1 || !isLabel(s[:end]) {
+		return "", 0
+	}
+
+	return s[:end], end + 1
+}
+
+// isLabel reports whether s is a node label like "#1", or a path ID like "#1.2.3".
+func isLabel(s string) bool {
+	if len(s) < 2 || s[0] != '#' {
+		return false
+	}
+
+	for _, part := range strings.Split(s[1:], ".") {
+		if !isDigits(part) {
+			return false
+		}
+	}
+
+	return true
+}
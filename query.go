@@ -0,0 +1,365 @@
+package erax
+
+import (
+	"sort"
+	"strings"
+)
+
+// QueryExpr is a compiled query over error trees. See Query for the syntax.
+//
+// A QueryExpr is immutable, so it can be compiled once and evaluated concurrently.
+type QueryExpr struct {
+	steps []queryStep
+}
+
+// Query evaluates a JSONPath-like query over an error tree, for example:
+//
+//	$..errs[*].meta.code
+//	$..[?(@.meta.code ^= "5")].message
+//
+// v is either an error or a map produced by FormatToJSONMap. Every node of the tree has the fields
+// "message", "meta" (its metadata, with one field per key), "errs" (the errors of a WrapWithErrors node)
+// and "cause". In maps, a "cause" list holds the errors of a WrapWithErrors node, the same way FromJSONMap reads it.
+//
+// The query is made of steps applied one after another, starting at the root node "$".
+// The root can be omitted, and so can the dot before the first field, as in "cause.message":
+//
+//   - .name or ['name'] selects a field of a node, or a metadata value by key
+//   - .* or [*] selects all children of a node (its errors, then its cause), all elements of "errs",
+//     or all metadata values
+//   - [n] selects an element of "errs", counting from the end if n is negative
+//   - [?(@.path op "value")] selects the children of a node, or the elements of "errs", for which the relative path
+//     matches a value with op: == (equal), != (not equal), ^= (starts with), $= (ends with) or *= (contains).
+//     Without op and value, [?(@.path)] selects the ones where the path matches anything
+//   - ..step applies the step to the current nodes and all the nodes below them
+//
+// Returns the selected values: strings, metadata as []MetaField, and nodes as Node for errors
+// or as the original maps for maps. Lists of nodes are returned as []any.
+// Returns an error if the query can't be parsed.
+func Query(v any, expr string) ([]any, error) {
+	q, err := CompileQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Eval(v), nil
+}
+
+// CompileQuery parses a query, so it can be evaluated many times. See Query for the syntax.
+//
+// Returns an error if the query can't be parsed.
+func CompileQuery(expr string) (*QueryExpr, error) {
+	p := queryParser{s: expr}
+
+	steps, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryExpr{steps: steps}, nil
+}
+
+// Eval evaluates the query over an error or a map produced by FormatToJSONMap. See Query for the results.
+func (q *QueryExpr) Eval(v any) []any {
+	root := queryRoot(v)
+	if root == nil {
+		return nil
+	}
+
+	values := evalSteps([]any{root}, q.steps)
+	if len(values) == 0 {
+		return nil
+	}
+
+	for i, value := range values {
+		values[i] = queryResult(value)
+	}
+
+	return values
+}
+
+// queryRoot returns the root node of the value a query is evaluated over, or nil if it isn't a tree.
+func queryRoot(v any) Node {
+	switch v := v.(type) {
+	case Node:
+		return v
+	case error:
+		return Inspect(v)
+	case map[string]any:
+		if v == nil {
+			return nil
+		}
+		return jsonNode{m: v}
+	}
+
+	return nil
+}
+
+// queryResult converts an intermediate value of a query to the value it returns.
+func queryResult(v any) any {
+	switch v := v.(type) {
+	case jsonNode:
+		return v.m
+	case []Node:
+		res := make([]any, len(v))
+		for i, n := range v {
+			res[i] = queryResult(n)
+		}
+		return res
+	}
+
+	return v
+}
+
+func evalSteps(values []any, steps []queryStep) []any {
+	for _, step := range steps {
+		if len(values) == 0 {
+			return nil
+		}
+
+		if step.recursive {
+			values = queryDescendants(values)
+		}
+
+		var next []any
+		for _, value := range values {
+			next = step.apply(value, next)
+		}
+		values = next
+	}
+
+	return values
+}
+
+// apply appends the values the step selects from v to dst.
+func (s *queryStep) apply(v any, dst []any) []any {
+	switch s.kind {
+	case queryField:
+		return selectField(v, s.name, dst)
+	case queryWildcard:
+		switch v := v.(type) {
+		case Node:
+			return appendNodeChildren(dst, v)
+		case []Node:
+			for _, n := range v {
+				dst = append(dst, n)
+			}
+		case []MetaField:
+			for _, field := range v {
+				dst = append(dst, field.Value)
+			}
+		}
+	case queryIndex:
+		if nodes, ok := v.([]Node); ok {
+			i := s.index
+			if i < 0 {
+				i += len(nodes)
+			}
+			if i >= 0 && i < len(nodes) {
+				dst = append(dst, nodes[i])
+			}
+		}
+	case queryFilter:
+		var candidates []any
+		switch v := v.(type) {
+		case Node:
+			candidates = appendNodeChildren(nil, v)
+		case []Node:
+			for _, n := range v {
+				candidates = append(candidates, n)
+			}
+		}
+
+		for _, candidate := range candidates {
+			if s.filter.matches(candidate) {
+				dst = append(dst, candidate)
+			}
+		}
+	}
+
+	return dst
+}
+
+// selectField appends the field of v with the given name to dst, if it has one.
+func selectField(v any, name string, dst []any) []any {
+	switch v := v.(type) {
+	case Node:
+		switch name {
+		case "message":
+			return append(dst, v.Message())
+		case "meta":
+			if meta := v.Meta(); len(meta) > 0 {
+				return append(dst, meta)
+			}
+		case "errs":
+			if errs := v.Errors(); len(errs) > 0 {
+				return append(dst, errs)
+			}
+		case "cause":
+			if cause := v.Cause(); cause != nil {
+				return append(dst, cause)
+			}
+		}
+	case []MetaField:
+		// The last field wins, the same way GetMeta searches from the most recent one.
+		for i := len(v) - 1; i >= 0; i-- {
+			if v[i].Key == name {
+				return append(dst, v[i].Value)
+			}
+		}
+	}
+
+	return dst
+}
+
+// matches reports whether the relative path of the filter selects a value satisfying its condition.
+func (f *queryCondition) matches(v any) bool {
+	for _, value := range evalSteps([]any{v}, f.path) {
+		if f.op == "" {
+			return true
+		}
+
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		switch f.op {
+		case "==":
+			ok = s == f.value
+		case "!=":
+			ok = s != f.value
+		case "^=":
+			ok = strings.HasPrefix(s, f.value)
+		case "$=":
+			ok = strings.HasSuffix(s, f.value)
+		case "*=":
+			ok = strings.Contains(s, f.value)
+		}
+
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// queryDescendants returns the values with all the nodes below them, parents before their children.
+//
+// Errors reachable more than once are returned once, so cyclic trees are safe.
+func queryDescendants(values []any) []any {
+	var seen visitSet
+	var res []any
+
+	for _, v := range values {
+		var stack []Node
+		switch v := v.(type) {
+		case Node:
+			stack = append(stack, v)
+		case []Node:
+			res = append(res, v)
+			for i := len(v) - 1; i >= 0; i-- {
+				stack = append(stack, v[i])
+			}
+		default:
+			res = append(res, v)
+		}
+
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if err := n.Err(); err != nil && !seen.add(err) {
+				continue
+			}
+			res = append(res, n)
+
+			children := appendNodeChildren(nil, n)
+			for i := len(children) - 1; i >= 0; i-- {
+				stack = append(stack, children[i].(Node))
+			}
+		}
+	}
+
+	return res
+}
+
+// appendNodeChildren appends the children of a node to dst: its errors, then its cause.
+func appendNodeChildren(dst []any, n Node) []any {
+	for _, child := range n.Errors() {
+		dst = append(dst, child)
+	}
+	if cause := n.Cause(); cause != nil {
+		dst = append(dst, cause)
+	}
+	return dst
+}
+
+// jsonNode is the Node view of a map produced by FormatToJSONMap, or decoded from the output of FormatToJSONString.
+type jsonNode struct {
+	m map[string]any
+}
+
+func (n jsonNode) Message() string {
+	msg, _ := n.m["message"].(string)
+	return msg
+}
+
+func (n jsonNode) Meta() []MetaField {
+	switch meta := n.m["meta"].(type) {
+	case []MetaField:
+		return meta
+	case map[string]any:
+		// Decoded JSON objects lose the order of their keys, so they are sorted to keep queries stable.
+		fields := make([]MetaField, 0, len(meta))
+		for k, v := range meta {
+			if s, ok := v.(string); ok {
+				fields = append(fields, MetaField{Key: k, Value: s})
+			}
+		}
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+		return fields
+	}
+
+	return nil
+}
+
+func (n jsonNode) Cause() Node {
+	if cause, ok := n.m["cause"].(map[string]any); ok && cause != nil {
+		return jsonNode{m: cause}
+	}
+	return nil
+}
+
+func (n jsonNode) Errors() []Node {
+	var nodes []Node
+
+	switch errs := n.m["cause"].(type) {
+	case []map[string]any:
+		for _, m := range errs {
+			nodes = append(nodes, jsonNode{m: m})
+		}
+	case []any:
+		for _, v := range errs {
+			if m, ok := v.(map[string]any); ok {
+				nodes = append(nodes, jsonNode{m: m})
+			}
+		}
+	}
+
+	return nodes
+}
+
+// IsForeign reports whether the map holds nothing but a message, the same way FromJSONMap restores it as a plain error.
+func (n jsonNode) IsForeign() bool {
+	for _, key := range [...]string{"meta", "cause", "anchor", "ref"} {
+		if _, ok := n.m[key]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns nil: a map is not an error.
+func (n jsonNode) Err() error { return nil }
@@ -0,0 +1,276 @@
+package erax
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type queryStepKind int
+
+const (
+	queryField queryStepKind = iota
+	queryWildcard
+	queryIndex
+	queryFilter
+)
+
+// queryStep is a single step of a compiled query.
+type queryStep struct {
+	kind queryStepKind
+	// recursive is set for steps written after "..".
+	recursive bool
+
+	name   string
+	index  int
+	filter *queryCondition
+}
+
+// queryCondition is the condition of a [?(...)] filter.
+type queryCondition struct {
+	path []queryStep
+	// op is empty for existence checks.
+	op    string
+	value string
+}
+
+// queryParser parses the query syntax described by Query.
+type queryParser struct {
+	s   string
+	pos int
+}
+
+func (p *queryParser) parse() ([]queryStep, error) {
+	p.skipSpaces()
+	p.consume("$")
+
+	// The first field can be written without a dot, as in "cause.message".
+	var steps []queryStep
+	if p.pos < len(p.s) && isQueryNameChar(p.s[p.pos]) {
+		step, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	rest, err := p.parseSteps()
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, rest...)
+
+	p.skipSpaces()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+
+	return steps, nil
+}
+
+// parseSteps parses steps until the first character that can't start one.
+func (p *queryParser) parseSteps() ([]queryStep, error) {
+	var steps []queryStep
+
+	for p.pos < len(p.s) {
+		var step queryStep
+		var err error
+
+		switch {
+		case p.consume(".."):
+			if p.peek() == '[' {
+				step, err = p.parseBracket()
+			} else {
+				step, err = p.parseName()
+			}
+			step.recursive = true
+		case p.consume("."):
+			step, err = p.parseName()
+		case p.peek() == '[':
+			step, err = p.parseBracket()
+		default:
+			return steps, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// parseName parses a field name or "*" after a dot.
+func (p *queryParser) parseName() (queryStep, error) {
+	if p.consume("*") {
+		return queryStep{kind: queryWildcard}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && isQueryNameChar(p.s[p.pos]) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return queryStep{}, p.errorf("expected a field name")
+	}
+
+	return queryStep{kind: queryField, name: p.s[start:p.pos]}, nil
+}
+
+// parseBracket parses a [...] step: a wildcard, an index, a quoted name or a filter.
+func (p *queryParser) parseBracket() (queryStep, error) {
+	p.consume("[")
+	p.skipSpaces()
+
+	var step queryStep
+
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		step.kind = queryWildcard
+	case c == '\'' || c == '"':
+		name, err := p.parseString()
+		if err != nil {
+			return queryStep{}, err
+		}
+		step.kind, step.name = queryField, name
+	case c == '?':
+		p.pos++
+		cond, err := p.parseFilter()
+		if err != nil {
+			return queryStep{}, err
+		}
+		step.kind, step.filter = queryFilter, cond
+	default:
+		start := p.pos
+		p.consume("-")
+		for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			p.pos++
+		}
+
+		index, err := strconv.Atoi(p.s[start:p.pos])
+		if err != nil {
+			p.pos = start
+			return queryStep{}, p.errorf("expected \"*\", an index, a quoted name or a filter")
+		}
+		step.kind, step.index = queryIndex, index
+	}
+
+	p.skipSpaces()
+	if !p.consume("]") {
+		return queryStep{}, p.errorf("expected \"]\"")
+	}
+
+	return step, nil
+}
+
+// parseFilter parses the "(@.path op value)" part of a filter.
+func (p *queryParser) parseFilter() (*queryCondition, error) {
+	p.skipSpaces()
+	if !p.consume("(") {
+		return nil, p.errorf("expected \"(\"")
+	}
+
+	p.skipSpaces()
+	if !p.consume("@") {
+		return nil, p.errorf("expected \"@\"")
+	}
+
+	path, err := p.parseSteps()
+	if err != nil {
+		return nil, err
+	}
+
+	cond := &queryCondition{path: path}
+
+	p.skipSpaces()
+	for _, op := range [...]string{"==", "!=", "^=", "$=", "*="} {
+		if !p.consume(op) {
+			continue
+		}
+
+		p.skipSpaces()
+		cond.op = op
+
+		if c := p.peek(); c == '\'' || c == '"' {
+			cond.value, err = p.parseString()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			start := p.pos
+			for p.pos < len(p.s) && isQueryNameChar(p.s[p.pos]) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, p.errorf("expected a value after %q", op)
+			}
+			cond.value = p.s[start:p.pos]
+		}
+		break
+	}
+
+	p.skipSpaces()
+	if !p.consume(")") {
+		return nil, p.errorf("expected \")\"")
+	}
+
+	return cond, nil
+}
+
+// parseString parses a single- or double-quoted string, where a backslash escapes the next character.
+func (p *queryParser) parseString() (string, error) {
+	quote := p.s[p.pos]
+	p.pos++
+
+	var sb strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+
+		switch {
+		case c == quote:
+			return sb.String(), nil
+		case c == '\\' && p.pos < len(p.s):
+			sb.WriteByte(p.s[p.pos])
+			p.pos++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+func (p *queryParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+// consume skips s if the input continues with it.
+func (p *queryParser) consume(s string) bool {
+	if !strings.HasPrefix(p.s[p.pos:], s) {
+		return false
+	}
+
+	p.pos += len(s)
+	return true
+}
+
+func (p *queryParser) skipSpaces() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("erax: invalid query at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func isQueryNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
@@ -0,0 +1,32 @@
+package erax
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestQueryDecodedJSON(t *testing.T) {
+	cache := WrapWithErrors(nil, "cache failed", WithMeta(New("timeout"), "redis error", F("code", "500")))
+	err := WrapWithErrors(nil, "failed to load user",
+		WithMeta(New("db timeout"), "db error", F("code", "503")),
+		WithMeta(New("bad token"), "auth error", F("code", "400")),
+		cache,
+	)
+
+	var m map[string]any
+	if e := json.Unmarshal([]byte(FormatToJSONString(err)), &m); e != nil {
+		t.Fatal(e)
+	}
+
+	want := "[503 400 500]"
+	for name, v := range map[string]any{"error": err, "map": FormatToJSONMap(err), "decoded": m} {
+		got, e := Query(v, "$..errs[*].meta.code")
+		if e != nil {
+			t.Fatal(e)
+		}
+		if fmt.Sprint(got) != want {
+			t.Errorf("Query(%s) = %v, want %s", name, got, want)
+		}
+	}
+}
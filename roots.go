@@ -0,0 +1,130 @@
+package erax
+
+import "fmt"
+
+// RootCauses returns the leaves of the error tree: the errors that don't wrap anything else.
+//
+// The leaves are returned in the order Format renders them. A leaf reachable more than once is returned once.
+func RootCauses(err error) []error {
+	var leaves []error
+	walkRootCauses(err, func(leaf error, _ []*errorType) {
+		leaves = append(leaves, leaf)
+	})
+	return leaves
+}
+
+// FormatInverted pretty-prints the error trace root cause first.
+//
+// Every leaf returned by RootCauses is rendered on its own, followed by the errors that wrapped it,
+// from the innermost one to the outermost one. Metadata stays on the error it was attached to.
+//
+// A leaf reachable more than once is rendered once, with the first path it was reached by.
+func FormatInverted(err error) string {
+	e, isErax := asErax(err)
+	if !isErax {
+		return Format(err)
+	}
+
+	inverted := &errorType{}
+	walkRootCauses(e, func(leaf error, ancestors []*errorType) {
+		inverted.errs = append(inverted.errs, invertPath(leaf, ancestors))
+	})
+
+	noun := " root causes"
+	if len(inverted.errs) == 1 {
+		noun = " root cause"
+	}
+	inverted.msg = formatCount(len(inverted.errs)) + noun
+
+	return Format(inverted)
+}
+
+// invertPath builds a chain starting at the leaf and continuing with its ancestors, from the closest one to the root.
+func invertPath(leaf error, ancestors []*errorType) *errorType {
+	head := &errorType{}
+	if e, isErax := asErax(leaf); isErax {
+		head.msg = e.msg
+		head.meta = e.meta
+	} else {
+		head.msg = fmt.Sprintf("%+v", leaf)
+	}
+
+	current := head
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		next := &errorType{msg: ancestors[i].msg, meta: ancestors[i].meta}
+		current.cause = next
+		current = next
+	}
+
+	return head
+}
+
+// rootCauseFrame is a node on the path to the current leaf, with its children and the index of the next one to visit.
+type rootCauseFrame struct {
+	children []error
+	next     int
+}
+
+// walkRootCauses calls fn for every leaf of the error tree with the nodes on the path to it, starting at the root.
+//
+// Leaves are visited in the order Format renders them: the errors of a node first, then its cause.
+// Errors wrapped by non-erax errors are followed too, and such wrappers are passed as ancestors
+// with the part of the message they add. Nodes reachable more than once are visited once, so cycles are safe.
+func walkRootCauses(err error, fn func(leaf error, ancestors []*errorType)) {
+	if err == nil {
+		return
+	}
+
+	children := appendChildren(nil, err)
+	if len(children) == 0 {
+		fn(err, nil)
+		return
+	}
+
+	var seen visitSet
+	seen.add(err)
+
+	stack := []rootCauseFrame{{children: children}}
+	ancestors := []*errorType{rootCauseAncestor(err, children)}
+
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next == len(top.children) {
+			stack = stack[:len(stack)-1]
+			ancestors = ancestors[:len(ancestors)-1]
+			continue
+		}
+
+		child := top.children[top.next]
+		top.next++
+
+		if !seen.add(child) {
+			continue
+		}
+
+		children := appendChildren(nil, child)
+		if len(children) == 0 {
+			fn(child, ancestors)
+			continue
+		}
+
+		stack = append(stack, rootCauseFrame{children: children})
+		ancestors = append(ancestors, rootCauseAncestor(child, children))
+	}
+}
+
+// rootCauseAncestor returns the erax node standing for err on the path to a leaf.
+//
+// Erax errors are returned as they are. Other errors are replaced with a node holding the part of their message
+// added by the error itself, the same way Cast converts them.
+func rootCauseAncestor(err error, children []error) *errorType {
+	if e, isErax := asErax(err); isErax {
+		return e
+	}
+
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		return &errorType{msg: joinedOwnMessage(err, children), origin: err}
+	}
+
+	return &errorType{msg: ownMessage(err, children[0]), origin: err}
+}
@@ -0,0 +1,31 @@
+package erax
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRootCausesFollowsForeignChains(t *testing.T) {
+	err := Wrap(fmt.Errorf("read: %w", io.EOF), "top")
+
+	causes := RootCauses(err)
+	if len(causes) != 1 || causes[0] != io.EOF {
+		t.Fatalf("RootCauses() = %v, want [EOF]", causes)
+	}
+
+	if got := FormatInverted(err); !strings.Contains(got, "read") {
+		t.Errorf("FormatInverted() lost the foreign wrapper:\n%s", got)
+	}
+}
+
+func TestRootCausesFollowsJoinInsideErax(t *testing.T) {
+	err := Wrap(errors.Join(io.EOF, io.ErrUnexpectedEOF), "top")
+
+	causes := RootCauses(err)
+	if len(causes) != 2 || causes[0] != io.EOF || causes[1] != io.ErrUnexpectedEOF {
+		t.Fatalf("RootCauses() = %v, want [EOF unexpected EOF]", causes)
+	}
+}
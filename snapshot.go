@@ -0,0 +1,52 @@
+package erax
+
+import (
+	"bytes"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// FormatSnapshot renders the error tree the same way on every machine, for golden files and other snapshots.
+//
+// The snapshot holds the uncolored trace, a blank line and the JSON indented with two spaces.
+// Both are rendered with the default settings, whatever SetWidth, SetAlignMeta, SetMaxDepth, SetMaxChildren,
+// SetMaxLines, SetNodeIDs and the theme are set to, and without hyperlinks.
+func FormatSnapshot(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	trace := bufferPool.Get().(*bytes.Buffer)
+	trace.Reset()
+
+	tw := newTraceWriter(trace)
+	tw.width = 0
+	tw.links = false
+	tw.align = false
+	tw.limits = limits{}
+	writeTrace(tw, err, "")
+	_, _ = tw.close()
+
+	src := bufferPool.Get().(*bytes.Buffer)
+	src.Reset()
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	var refs nodeRefs
+	if e, isErax := asErax(err); isErax {
+		refs = newNodeRefs(e)
+	}
+
+	writeErrorJSON(src, err, 1, limits{}, &refs, "")
+	writePrettyJSON(buf, src.Bytes(), "  ")
+
+	res := ansi.Strip(trace.String()) + "\n\n" + ansi.Strip(buf.String()) + "\n"
+
+	for _, b := range [...]*bytes.Buffer{trace, src, buf} {
+		if b.Cap() <= 16384 {
+			bufferPool.Put(b)
+		}
+	}
+
+	return res
+}
@@ -8,6 +8,13 @@ func SetAlienColor(color lipgloss.Color) {
 	alienText = lipgloss.NewStyle().Foreground(alienColor)
 }
 
+// SetAlignMeta enables or disables aligning metadata values within each error's metadata block.
+//
+// When enabled, values start at the same column, right after the longest key.
+func SetAlignMeta(align bool) {
+	alignMeta = align
+}
+
 // SetBranchColor sets the color for tree branch characters in formatted output.
 func SetBranchColor(color lipgloss.Color) {
 	branchColor = color
@@ -19,7 +26,8 @@ func SetBranchColor(color lipgloss.Color) {
 	branchEndBig = lipgloss.NewStyle().Foreground(branchColor).Render(" ╰── ")
 	branchNext = lipgloss.NewStyle().Foreground(branchColor).Render("├─ ")
 	branchEnd = lipgloss.NewStyle().Foreground(branchColor).Render("╰─ ")
-	message = lipgloss.NewStyle().Foreground(branchColor).Render(" ▼ [ERROR TRACE]")
+	message = lipgloss.NewStyle().Foreground(branchColor).Render(traceHeader)
+	elisionText = lipgloss.NewStyle().Foreground(branchColor)
 }
 
 // SetErrorColor sets the color for erax error messages in formatted output.
@@ -28,6 +36,13 @@ func SetErrorColor(color lipgloss.Color) {
 	errorText = lipgloss.NewStyle().Foreground(errorColor)
 }
 
+// SetJSONColor enables or disables coloring the output of FormatJSONPretty with the key and value colors.
+//
+// Colored output is meant for terminals only: it is not valid JSON anymore.
+func SetJSONColor(color bool) {
+	jsonColor = color
+}
+
 // SetKeyColor sets the color for metadata keys in formatted output.
 func SetKeyColor(color lipgloss.Color) {
 	keyColor = color
@@ -40,6 +55,14 @@ func SetValueColor(color lipgloss.Color) {
 	valueText = lipgloss.NewStyle().Foreground(valueColor)
 }
 
+// traceHeader is the first line of every error trace.
+const traceHeader = " ▼ [ERROR TRACE]"
+
+var (
+	alignMeta = false
+	jsonColor = false
+)
+
 var (
 	alienColor  lipgloss.Color = "#89b4fa"
 	branchColor lipgloss.Color = "#585b70"
@@ -56,10 +79,11 @@ var (
 	branchEndBig  = lipgloss.NewStyle().Foreground(branchColor).Render(" ╰── ")
 	branchNext    = lipgloss.NewStyle().Foreground(branchColor).Render("├─ ")
 	branchEnd     = lipgloss.NewStyle().Foreground(branchColor).Render("╰─ ")
-	message       = lipgloss.NewStyle().Foreground(branchColor).Render(" ▼ [ERROR TRACE]")
+	message       = lipgloss.NewStyle().Foreground(branchColor).Render(traceHeader)
 
-	alienText = lipgloss.NewStyle().Foreground(alienColor)
-	errorText = lipgloss.NewStyle().Foreground(errorColor)
-	keyText   = lipgloss.NewStyle().Foreground(keyColor)
-	valueText = lipgloss.NewStyle().Foreground(valueColor)
+	alienText   = lipgloss.NewStyle().Foreground(alienColor)
+	elisionText = lipgloss.NewStyle().Foreground(branchColor)
+	errorText   = lipgloss.NewStyle().Foreground(errorColor)
+	keyText     = lipgloss.NewStyle().Foreground(keyColor)
+	valueText   = lipgloss.NewStyle().Foreground(valueColor)
 )
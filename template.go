@@ -0,0 +1,88 @@
+package erax
+
+import (
+	"bytes"
+	"io"
+	"text/template"
+)
+
+// TemplateFormatter renders error trees with a text/template, for custom trace layouts.
+//
+// The template is executed with the root *TemplateNode. Besides the standard functions,
+// templates can use the following helpers:
+//
+//   - indent N: 2*N spaces, for example {{indent .Depth}}
+//   - indentLines N TEXT: TEXT with every line but the first indented by 2*N spaces
+//   - styleError, styleForeign, styleKey, styleValue, styleBranch TEXT: TEXT colored with the current theme
+//   - walk NODE: NODE and all the nodes below it, in the order Format renders them,
+//     so a template can render the whole tree with a single range instead of recursion
+//
+// For example:
+//
+//	{{range walk .}}{{indent .Depth}}{{styleError .Message}}{{range .Meta}} {{styleKey .Key}}={{styleValue .Value}}{{end}}
+//	{{end}}
+type TemplateFormatter struct {
+	tmpl *template.Template
+}
+
+// TemplateNode is a single error of the tree, as seen by the template of a TemplateFormatter.
+type TemplateNode struct {
+	// Message is the message of the error. For non-erax errors it's the output of %+v.
+	Message string
+	// Meta holds the metadata fields of the error.
+	Meta []MetaField
+	// Children holds the errors of a WrapWithErrors node followed by the cause, the same way Format renders them.
+	Children []*TemplateNode
+	// Depth is the depth of the node in the tree. The root is at depth 1.
+	Depth int
+	// IsLast is set for the last child of a node, and for the root.
+	IsLast bool
+	// IsCause is set if the node is the cause of its parent, not one of its errors.
+	IsCause bool
+	// IsForeign is set for non-erax errors.
+	IsForeign bool
+	// Path holds the indexes of the node and its ancestors in the Children of their parents, starting below the root.
+	// The root has an empty path.
+	Path []int
+	// Label is set for errors reachable more than once, like "#1". They are rendered once, with their children.
+	Label string
+	// Ref is set for later occurrences of an error reachable more than once, like "(see #1)" or "(cycle to #1)".
+	// A back-reference has no children.
+	Ref string
+}
+
+// NewTemplateFormatter parses a text/template used to render error trees.
+//
+// Returns an error if the template can't be parsed.
+func NewTemplateFormatter(tmpl string) (*TemplateFormatter, error) {
+	t, err := template.New("erax").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TemplateFormatter{tmpl: t}, nil
+}
+
+// Format renders an error tree with the template.
+//
+// Returns an error if the template fails to execute.
+func (f *TemplateFormatter) Format(err error) (string, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	execErr := f.Execute(buf, err)
+	res := buf.String()
+
+	if buf.Cap() <= 16384 {
+		bufferPool.Put(buf)
+	}
+
+	return res, execErr
+}
+
+// Execute renders an error tree with the template straight to w.
+//
+// Returns an error if the template fails to execute or w fails to write.
+func (f *TemplateFormatter) Execute(w io.Writer, err error) error {
+	return f.tmpl.Execute(w, newTemplateTree(err))
+}
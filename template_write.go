@@ -0,0 +1,125 @@
+package erax
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var templateFuncs = template.FuncMap{
+	"indent":       templateIndent,
+	"indentLines":  templateIndentLines,
+	"styleError":   func(s string) string { return errorText.Render(s) },
+	"styleForeign": func(s string) string { return alienText.Render(s) },
+	"styleKey":     func(s string) string { return keyText.Render(s) },
+	"styleValue":   func(s string) string { return valueText.Render(s) },
+	"styleBranch":  func(s string) string { return elisionText.Render(s) },
+	"walk":         templateWalk,
+}
+
+// newTemplateTree builds the node model of the error tree. Returns nil for a nil error.
+func newTemplateTree(err error) *TemplateNode {
+	if err == nil {
+		return nil
+	}
+
+	var refs nodeRefs
+	if e, isErax := asErax(err); isErax {
+		refs = newNodeRefs(e)
+	}
+
+	root := newTemplateNode(err, 1, nil, &refs)
+	root.IsLast = true
+
+	return root
+}
+
+func newTemplateNode(err error, depth int, path []int, refs *nodeRefs) *TemplateNode {
+	n := &TemplateNode{Depth: depth, Path: path}
+
+	e, isErax := asErax(err)
+	if !isErax {
+		n.Message = fmt.Sprintf("%+v", err)
+		n.IsForeign = true
+		return n
+	}
+
+	n.Message = e.msg
+	n.Meta = e.meta
+
+	if ref := refs.seen(e); ref != nil {
+		n.Ref = ref.backRef()
+		return n
+	}
+
+	if ref := refs.enter(e); ref != nil {
+		defer refs.leave(ref)
+		n.Label = ref.id
+	}
+
+	children := len(e.errs)
+	if e.cause != nil {
+		children++
+	}
+	if children == 0 {
+		return n
+	}
+
+	n.Children = make([]*TemplateNode, 0, children)
+	for _, ue := range e.errs {
+		n.Children = append(n.Children, newTemplateNode(ue, depth+1, childPath(path, len(n.Children)), refs))
+	}
+	if e.cause != nil {
+		cause := newTemplateNode(e.cause, depth+1, childPath(path, len(n.Children)), refs)
+		cause.IsCause = true
+		n.Children = append(n.Children, cause)
+	}
+
+	n.Children[len(n.Children)-1].IsLast = true
+
+	return n
+}
+
+// childPath returns the path of the i-th child of the node at path, without sharing memory with it.
+func childPath(path []int, i int) []int {
+	child := make([]int, len(path)+1)
+	copy(child, path)
+	child[len(path)] = i
+	return child
+}
+
+func templateIndent(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("  ", n)
+}
+
+func templateIndentLines(n int, s string) string {
+	if n <= 0 {
+		return s
+	}
+	return strings.ReplaceAll(s, "\n", "\n"+strings.Repeat("  ", n))
+}
+
+// templateWalk returns the node and all the nodes below it, parents before their children.
+func templateWalk(root *TemplateNode) []*TemplateNode {
+	if root == nil {
+		return nil
+	}
+
+	var nodes []*TemplateNode
+	stack := []*TemplateNode{root}
+
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nodes = append(nodes, n)
+
+		for i := len(n.Children) - 1; i >= 0; i-- {
+			stack = append(stack, n.Children[i])
+		}
+	}
+
+	return nodes
+}
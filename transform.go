@@ -0,0 +1,278 @@
+package erax
+
+import "strconv"
+
+// Map returns a copy of the error tree with the message and metadata of every erax error replaced by fn.
+//
+// fn receives the original error and returns the new message and metadata. Non-erax errors are kept as they are.
+// The original tree is left untouched. Errors reachable more than once are copied once, so shared errors
+// stay shared and cycles are preserved.
+func Map(err error, fn func(n Node) (string, []MetaField)) error {
+	return mapNode(err, fn, make(map[*errorType]*errorType))
+}
+
+func mapNode(err error, fn func(n Node) (string, []MetaField), done map[*errorType]*errorType) error {
+	e, isErax := asErax(err)
+	if !isErax {
+		return err
+	}
+
+	if c, ok := done[e]; ok {
+		return c
+	}
+
+	c := &errorType{origin: e.origin, truncated: e.truncated}
+	done[e] = c
+
+	var meta []MetaField
+	c.msg, meta = fn(e)
+	c.meta = cloneMeta(meta)
+
+	if len(e.errs) > 0 {
+		c.errs = make([]error, len(e.errs))
+		for i, ue := range e.errs {
+			c.errs[i] = mapNode(ue, fn, done)
+		}
+	}
+
+	if e.cause != nil {
+		c.cause = mapNode(e.cause, fn, done)
+	}
+
+	return c
+}
+
+// Filter returns a copy of the error tree without the errors keep returns false for.
+//
+// The children of a removed error take its place: a removed cause is replaced with its own cause,
+// and a removed error of a WrapWithErrors node is replaced with all its children, in order.
+// If the root is removed and more than one error takes its place, they are wrapped into an erax error
+// without a message. Returns nil if nothing is left.
+//
+// Non-erax errors are leaves: removing one removes everything it wraps.
+// The original tree is left untouched, and errors reachable more than once are copied once.
+func Filter(err error, keep func(n Node) bool) error {
+	if err == nil {
+		return nil
+	}
+
+	f := filter{
+		keep: keep,
+		done: make(map[*errorType][]error),
+		open: make(map[*errorType]bool),
+	}
+
+	kept := f.node(err)
+	switch len(kept) {
+	case 0:
+		return nil
+	case 1:
+		return kept[0]
+	}
+
+	return &errorType{errs: kept}
+}
+
+// filter holds the state of a Filter call.
+type filter struct {
+	keep func(n Node) bool
+	// done holds the errors taking the place of every erax error already filtered.
+	done map[*errorType][]error
+	// open holds the removed errors being filtered, so cycles through them are cut.
+	open map[*errorType]bool
+}
+
+// node returns the errors taking the place of err in the filtered tree:
+// a copy of err itself, or the filtered children of err if it's removed.
+func (f *filter) node(err error) []error {
+	e, isErax := asErax(err)
+	if !isErax {
+		if f.keep(Inspect(err)) {
+			return []error{err}
+		}
+		return nil
+	}
+
+	if kept, ok := f.done[e]; ok {
+		return kept
+	}
+	if f.open[e] {
+		return nil
+	}
+
+	if !f.keep(e) {
+		f.open[e] = true
+
+		var kept []error
+		for _, ue := range e.errs {
+			kept = append(kept, f.node(ue)...)
+		}
+		if e.cause != nil {
+			kept = append(kept, f.node(e.cause)...)
+		}
+
+		delete(f.open, e)
+		f.done[e] = kept
+
+		return kept
+	}
+
+	c := &errorType{msg: e.msg, meta: cloneMeta(e.meta), origin: e.origin, truncated: e.truncated}
+	f.done[e] = []error{c}
+
+	for _, ue := range e.errs {
+		c.errs = append(c.errs, f.node(ue)...)
+	}
+
+	if e.cause != nil {
+		kept := f.node(e.cause)
+		if len(kept) == 1 && len(c.errs) == 0 {
+			c.cause = kept[0]
+		} else {
+			c.errs = append(c.errs, kept...)
+		}
+	}
+
+	return f.done[e]
+}
+
+// Prune returns a copy of the error tree without the errors deeper than maxDepth.
+//
+// The root is at depth 1, the same way as for SetMaxDepth. Errors at maxDepth lose their children.
+// Returns the error itself if maxDepth is less than 1. The original tree is left untouched.
+func Prune(err error, maxDepth int) error {
+	if maxDepth < 1 {
+		return err
+	}
+	return pruneNode(err, maxDepth)
+}
+
+// pruneNode copies the error with its children, down to the given number of levels.
+//
+// The number of levels is bounded, so there is no need to track shared errors or cycles:
+// every occurrence is copied on its own.
+func pruneNode(err error, levels int) error {
+	e, isErax := asErax(err)
+	if !isErax {
+		return err
+	}
+
+	c := &errorType{msg: e.msg, meta: cloneMeta(e.meta), origin: e.origin, truncated: e.truncated}
+	if levels == 1 {
+		return c
+	}
+
+	if len(e.errs) > 0 {
+		c.errs = make([]error, len(e.errs))
+		for i, ue := range e.errs {
+			c.errs[i] = pruneNode(ue, levels-1)
+		}
+	}
+
+	if e.cause != nil {
+		c.cause = pruneNode(e.cause, levels-1)
+	}
+
+	return c
+}
+
+// CollapseDuplicates returns a copy of the error tree where identical errors of every WrapWithErrors node
+// are merged into one.
+//
+// Errors are identical if they render the same way: same messages, metadata and children.
+// The merged error gets a "count" metadata field with the number of errors merged into it;
+// a non-erax error is wrapped into an erax error without a message to carry it.
+//
+// The original tree is left untouched, and errors reachable more than once are copied once.
+func CollapseDuplicates(err error) error {
+	return collapseNode(err, make(map[*errorType]*errorType))
+}
+
+func collapseNode(err error, done map[*errorType]*errorType) error {
+	e, isErax := asErax(err)
+	if !isErax {
+		return err
+	}
+
+	if c, ok := done[e]; ok {
+		return c
+	}
+
+	c := &errorType{msg: e.msg, meta: cloneMeta(e.meta), origin: e.origin, truncated: e.truncated}
+	done[e] = c
+
+	if len(e.errs) > 0 {
+		errs := make([]error, len(e.errs))
+		for i, ue := range e.errs {
+			errs[i] = collapseNode(ue, done)
+		}
+		c.errs = collapseSiblings(errs)
+	}
+
+	if e.cause != nil {
+		c.cause = collapseNode(e.cause, done)
+	}
+
+	return c
+}
+
+// collapseSiblings merges identical errors, keeping the first occurrence of each in place.
+func collapseSiblings(errs []error) []error {
+	if len(errs) < 2 {
+		return errs
+	}
+
+	keys := make([]string, len(errs))
+	counts := make(map[string]int, len(errs))
+	for i, ue := range errs {
+		keys[i] = FormatCompact(ue)
+		counts[keys[i]]++
+	}
+
+	if len(counts) == len(errs) {
+		return errs
+	}
+
+	collapsed := make([]error, 0, len(counts))
+	for i, ue := range errs {
+		n := counts[keys[i]]
+		if n == 0 {
+			continue
+		}
+		counts[keys[i]] = 0
+
+		if n > 1 {
+			ue = withCount(ue, n)
+		}
+		collapsed = append(collapsed, ue)
+	}
+
+	return collapsed
+}
+
+// withCount returns a shallow copy of the error with a "count" metadata field.
+func withCount(err error, n int) error {
+	count := F("count", strconv.Itoa(n))
+
+	e, isErax := asErax(err)
+	if !isErax {
+		return WithMeta(err, "", count)
+	}
+
+	return &errorType{
+		cause:     e.cause,
+		errs:      e.errs,
+		meta:      append(cloneMeta(e.meta), count),
+		msg:       e.msg,
+		origin:    e.origin,
+		truncated: e.truncated,
+	}
+}
+
+// cloneMeta returns a copy of the metadata, so the copy of an error never shares it with the original.
+func cloneMeta(meta []MetaField) []MetaField {
+	if len(meta) == 0 {
+		return nil
+	}
+	return append([]MetaField(nil), meta...)
+}
@@ -0,0 +1,174 @@
+package erax
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// visitSet is a set of errors already reached by a traversal.
+//
+// It protects traversals from cycles. Small sets live in a fixed array,
+// so walking a typical error tree does not allocate.
+type visitSet struct {
+	small [16]error
+	n     int
+	large map[error]struct{}
+}
+
+// add adds an error to the set. Returns false if it is already there.
+//
+// Errors that are not pointers are never added: they are copied by value,
+// so they can't be reached twice on their own.
+func (s *visitSet) add(err error) bool {
+	if !isTrackable(err) {
+		return true
+	}
+
+	if s.large != nil {
+		if _, ok := s.large[err]; ok {
+			return false
+		}
+		s.large[err] = struct{}{}
+		return true
+	}
+
+	for i := 0; i < s.n; i++ {
+		if s.small[i] == err {
+			return false
+		}
+	}
+
+	if s.n < len(s.small) {
+		s.small[s.n] = err
+		s.n++
+		return true
+	}
+
+	s.large = make(map[error]struct{}, 2*len(s.small))
+	for _, e := range s.small {
+		s.large[e] = struct{}{}
+	}
+	s.large[err] = struct{}{}
+
+	return true
+}
+
+// isTrackable reports whether an error has an identity that can be tracked by a visitSet.
+func isTrackable(err error) bool {
+	if _, ok := err.(*errorType); ok {
+		return true
+	}
+
+	t := reflect.TypeOf(err)
+	return t != nil && t.Kind() == reflect.Pointer
+}
+
+// nodeRef is the label of an erax node reachable more than once in the tree.
+type nodeRef struct {
+	// id is assigned when the node is rendered for the first time.
+	id string
+	// open is set while the node's subtree is being rendered, so reaching it again means a cycle.
+	open bool
+}
+
+// nodeRefs labels erax nodes reachable more than once, so each of them is rendered only once,
+// and later occurrences are rendered as back-references.
+type nodeRefs struct {
+	refs map[*errorType]*nodeRef
+	next int
+}
+
+// newNodeRefs finds the erax nodes reachable more than once from the root.
+//
+// The map is only allocated if there are any, so trees without shared nodes are cheap to render.
+func newNodeRefs(root *errorType) nodeRefs {
+	var refs nodeRefs
+	var seen visitSet
+
+	seen.add(root)
+	stack := [8]*errorType{root}
+	slice := stack[:1]
+
+	push := func(err error) {
+		next, isErax := asErax(err)
+		if !isErax {
+			return
+		}
+
+		if seen.add(next) {
+			slice = append(slice, next)
+			return
+		}
+
+		if refs.refs == nil {
+			refs.refs = make(map[*errorType]*nodeRef)
+		}
+		if refs.refs[next] == nil {
+			refs.refs[next] = &nodeRef{}
+		}
+	}
+
+	for len(slice) > 0 {
+		current := slice[len(slice)-1]
+		slice = slice[:len(slice)-1]
+
+		for _, ue := range current.errs {
+			push(ue)
+		}
+
+		if current.cause != nil {
+			push(current.cause)
+		}
+	}
+
+	return refs
+}
+
+// enter marks a node as being rendered. Returns its label if it is reachable more than once, or nil.
+//
+// The caller has to call leave once the node's subtree is rendered.
+func (r *nodeRefs) enter(e *errorType) *nodeRef {
+	return r.enterAt(e, "")
+}
+
+// enterAt is like enter, but labels the node with its path ID if it's not empty, instead of the next number.
+func (r *nodeRefs) enterAt(e *errorType, id string) *nodeRef {
+	ref := r.refs[e]
+	if ref == nil {
+		return nil
+	}
+
+	if id != "" {
+		ref.id = "#" + id
+	} else {
+		r.next++
+		ref.id = "#" + strconv.Itoa(r.next)
+	}
+	ref.open = true
+
+	return ref
+}
+
+// leave marks the subtree of a node returned by enter as rendered.
+func (r *nodeRefs) leave(ref *nodeRef) {
+	if ref != nil {
+		ref.open = false
+	}
+}
+
+// seen returns the label of a node that was already rendered, or nil if it wasn't.
+func (r *nodeRefs) seen(e *errorType) *nodeRef {
+	ref := r.refs[e]
+	if ref == nil || ref.id == "" {
+		return nil
+	}
+	return ref
+}
+
+// backRef returns the text rendered in place of an already rendered node.
+func (ref *nodeRef) backRef() string {
+	if ref.open {
+		return "(cycle to " + ref.id + ")"
+	}
+	return "(see " + ref.id + ")"
+}
@@ -0,0 +1,145 @@
+package erax
+
+// WalkAction tells Walk how to continue after visiting a node.
+type WalkAction int
+
+const (
+	// WalkContinue visits the children of the node, then the rest of the tree.
+	WalkContinue WalkAction = iota
+	// WalkSkip skips the children of the node, but visits the rest of the tree.
+	WalkSkip
+	// WalkStop stops the walk.
+	WalkStop
+)
+
+// WalkFunc is called by Walk for every node of the tree.
+//
+// path holds the indexes of the node and its ancestors among the children of their parents, starting below the root,
+// the same way as TemplateNode.Path. The children of a node are its errors followed by its cause.
+// depth is the depth of the node, the root is at depth 1.
+//
+// path is reused between calls, so it must be copied to be kept.
+type WalkFunc func(n Node, path []int, depth int) WalkAction
+
+// walkItem is an error waiting to be visited, with its depth and its index among the children of its parent.
+type walkItem struct {
+	err   error
+	depth int
+	index int
+}
+
+// Walk visits every node of the error tree depth-first, parents before their children,
+// in the order Format renders them.
+//
+// Both erax errors and non-erax errors are visited: the children of a non-erax error are the errors
+// it unwraps to with Unwrap() error or Unwrap() []error. Errors reachable more than once are visited only once,
+// so cyclic trees are safe. The walk doesn't use recursion, so trees of any depth can be walked.
+func Walk(err error, fn WalkFunc) {
+	if err == nil {
+		return
+	}
+
+	var seen visitSet
+	var path []int
+
+	stack := [8]walkItem{{err: err, depth: 1}}
+	slice := stack[:1]
+
+	var children []error
+	for len(slice) > 0 {
+		current := slice[len(slice)-1]
+		slice = slice[:len(slice)-1]
+
+		if !seen.add(current.err) {
+			continue
+		}
+
+		// The path of the parent is still at the start of path, as parents are visited before their children.
+		if current.depth == 1 {
+			path = path[:0]
+		} else {
+			path = append(path[:current.depth-2], current.index)
+		}
+
+		switch fn(Inspect(current.err), path, current.depth) {
+		case WalkStop:
+			return
+		case WalkSkip:
+			continue
+		}
+
+		// Children are pushed in reverse, so they are visited in order.
+		children = appendChildren(children[:0], current.err)
+		for i := len(children) - 1; i >= 0; i-- {
+			slice = append(slice, walkItem{err: children[i], depth: current.depth + 1, index: i})
+		}
+	}
+}
+
+// WalkBreadthFirst is like Walk, but visits the nodes level by level: the root first, then its children,
+// then their children and so on.
+func WalkBreadthFirst(err error, fn WalkFunc) {
+	if err == nil {
+		return
+	}
+
+	type queued struct {
+		err  error
+		path []int
+	}
+
+	var seen visitSet
+	queue := []queued{{err: err}}
+
+	var children []error
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if !seen.add(current.err) {
+			continue
+		}
+
+		switch fn(Inspect(current.err), current.path, len(current.path)+1) {
+		case WalkStop:
+			return
+		case WalkSkip:
+			continue
+		}
+
+		children = appendChildren(children[:0], current.err)
+		for i, child := range children {
+			queue = append(queue, queued{err: child, path: childPath(current.path, i)})
+		}
+	}
+}
+
+// appendChildren appends the children of an error to dst: the errors of an erax node followed by its cause,
+// or the errors a non-erax error unwraps to. Nil errors are left out, so fn is never called with a nil Node.
+func appendChildren(dst []error, err error) []error {
+	if e, isErax := asErax(err); isErax {
+		for _, next := range e.errs {
+			if next != nil {
+				dst = append(dst, next)
+			}
+		}
+		if e.cause != nil {
+			dst = append(dst, e.cause)
+		}
+		return dst
+	}
+
+	if w, ok := err.(interface{ Unwrap() error }); ok {
+		if next := w.Unwrap(); next != nil {
+			dst = append(dst, next)
+		}
+	} else if w, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, next := range w.Unwrap() {
+			if next != nil {
+				dst = append(dst, next)
+			}
+		}
+	}
+
+	return dst
+}
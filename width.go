@@ -0,0 +1,114 @@
+package erax
+
+import (
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+	"github.com/rivo/uniseg"
+)
+
+// WidthAuto makes the trace wrap to the width of the terminal it is written to.
+const WidthAuto = -1
+
+// minWrapWidth is the narrowest column wrapped lines are squeezed into.
+//
+// Deeply nested nodes may leave less room than that, in which case lines overflow instead.
+const minWrapWidth = 16
+
+var traceWidth = 0
+
+// SetWidth sets the maximum display width of the formatted error trace.
+//
+// Long messages and metadata values are wrapped to fit, keeping the tree lines intact.
+// Pass WidthAuto to detect the width of the terminal the trace is written to, or 0 to disable wrapping (the default).
+// With WidthAuto, traces written anywhere else, like files, buffers and the strings returned by Format, aren't wrapped,
+// so they don't depend on the terminal the program was started in.
+func SetWidth(width int) {
+	traceWidth = width
+}
+
+// resolveWidth returns the width the trace written to w should be wrapped to, or 0 if it should not be wrapped.
+//
+// With WidthAuto, only a terminal destination has a width.
+func resolveWidth(w io.Writer) int {
+	if traceWidth != WidthAuto {
+		return traceWidth
+	}
+
+	if f, ok := w.(interface{ Fd() uintptr }); ok && term.IsTerminal(f.Fd()) {
+		if width, _, err := term.GetSize(f.Fd()); err == nil {
+			return width
+		}
+	}
+
+	return 0
+}
+
+// advance moves the current column past the rendered string s.
+func (tw *traceWriter) advance(s string) {
+	if i := strings.LastIndexByte(s, '\n'); i != -1 {
+		tw.col = 0
+		s = s[i+1:]
+	}
+
+	tw.col += ansi.StringWidth(s)
+}
+
+// overflows reports whether a single line of plain text does not fit into the rest of the current row.
+func (tw *traceWriter) overflows(line string) bool {
+	if tw.width <= 0 {
+		return false
+	}
+
+	_, rest := tw.fit(line)
+	return rest != ""
+}
+
+// fit splits a single line of plain text into the part that fits into the rest of the current row
+// and the part that has to be wrapped onto the next one.
+//
+// Lines are broken at the last space that fits, or between graphemes if there is none.
+func (tw *traceWriter) fit(line string) (string, string) {
+	if tw.width <= 0 {
+		return line, ""
+	}
+
+	avail := tw.width - tw.col
+	if avail < minWrapWidth {
+		avail = minWrapWidth
+	}
+
+	width := 0
+	lastSpace := -1
+	rest := line
+	state := -1
+
+	for len(rest) > 0 {
+		var cluster string
+		var w int
+		cluster, rest, w, state = uniseg.FirstGraphemeClusterInString(rest, state)
+
+		if width+w > avail {
+			cut := len(line) - len(rest) - len(cluster)
+			if cluster == " " && cut > 0 {
+				return line[:cut], line[cut+1:]
+			}
+			if lastSpace > 0 {
+				return line[:lastSpace], line[lastSpace+1:]
+			}
+			if cut == 0 {
+				cut = len(cluster)
+			}
+			return line[:cut], line[cut:]
+		}
+
+		if cluster == " " {
+			lastSpace = len(line) - len(rest) - 1
+		}
+		width += w
+	}
+
+	return line, ""
+}